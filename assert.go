@@ -0,0 +1,55 @@
+package clockwork
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TB is the part of testing.TB which the assertion helpers use. Declaring it
+// here keeps the testing package out of binaries which link clockwork.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// PendingSleepers describes each pending sleeper, by its deadline, the time
+// left until it, and how it was created.
+func (fc *fakeClock) PendingSleepers() []string {
+	fc.l.RLock()
+	defer fc.l.RUnlock()
+	var pending []string
+	for _, s := range fc.sleepers {
+		until := s.Until()
+		desc := fmt.Sprintf("%v (in %v)", until, until.Sub(fc.time))
+		if s.label != "" {
+			desc += " from " + s.label
+		}
+		pending = append(pending, desc)
+	}
+	return pending
+}
+
+// AssertNoPending fails the test if any sleepers are still pending on fc,
+// listing their deadlines and how they were created. It is intended to be
+// deferred at the start of a test to catch timers which were never fired or
+// stopped.
+func AssertNoPending(tb TB, fc FakeClock) {
+	tb.Helper()
+	pending := fc.PendingSleepers()
+	if len(pending) == 0 {
+		return
+	}
+	tb.Errorf("%d pending sleepers at %v:\n\t%s", len(pending), fc.Now(), strings.Join(pending, "\n\t"))
+}
+
+// AssertSubsecond fails the test if got doesn't have the same fraction of a
+// second as want, which usually means code under test truncated a timestamp
+// to whole seconds, or to a coarser unit than intended. Start a FakeClock at a
+// time such as one with 123456789ns so that any truncation shows.
+func AssertSubsecond(tb TB, got, want time.Time) {
+	tb.Helper()
+	if got.Nanosecond() != want.Nanosecond() {
+		tb.Errorf("%v has %dns past the second, want %dns as in %v", got, got.Nanosecond(), want.Nanosecond(), want)
+	}
+}
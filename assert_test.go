@@ -0,0 +1,71 @@
+package clockwork
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingTB captures the failures reported through TB.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Error(args ...interface{}) {
+	for _, arg := range args {
+		r.errors = append(r.errors, arg.(string))
+	}
+}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertNoPending(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	defer AssertNoPending(t, fc)
+
+	fc.After(time.Second)
+	timer := fc.NewTimer(time.Hour)
+
+	tb := &recordingTB{TB: t}
+	AssertNoPending(tb, fc)
+	if len(tb.errors) != 1 {
+		t.Fatalf("got %d failures, want 1", len(tb.errors))
+	}
+	for _, want := range []string{"2 pending sleepers", "(in 1s) from After", "(in 1h0m0s) from NewTimer"} {
+		if !strings.Contains(tb.errors[0], want) {
+			t.Errorf("failure %q does not contain %q", tb.errors[0], want)
+		}
+	}
+
+	timer.Stop()
+	fc.Advance(time.Second)
+	if pending := fc.PendingSleepers(); pending != nil {
+		t.Errorf("PendingSleepers() = %q once none are pending, want nil", pending)
+	}
+}
+
+func TestAssertSubsecond(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2020, 1, 1, 12, 0, 0, 123456789, time.UTC)
+	fc := NewFakeClockAt(start)
+	fc.Advance(time.Second)
+	AssertSubsecond(t, fc.Now(), start)
+	fc.Set(start.Add(time.Hour))
+	AssertSubsecond(t, fc.Now(), start)
+	if got := fc.NowUnixNano() % int64(time.Second); got != 123456789 {
+		t.Errorf("NowUnixNano() has %dns past the second, want 123456789", got)
+	}
+
+	tb := &recordingTB{TB: t}
+	AssertSubsecond(tb, fc.Now().Truncate(time.Millisecond), start)
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "123000000ns past the second, want 123456789ns") {
+		t.Errorf("got failures %q for a truncated time", tb.errors)
+	}
+}
@@ -0,0 +1,446 @@
+// Package benbjohnson adapts clockwork clocks to and from the Clock interface
+// of github.com/benbjohnson/clock, so that code written against either can be
+// driven by the other. It lives in its own module so that clockwork itself
+// doesn't depend on benbjohnson/clock.
+package benbjohnson
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	bjclock "github.com/benbjohnson/clock"
+	"github.com/jangala-dev/clockwork"
+)
+
+// AsBenbjohnson returns a bjclock.Clock which reads and waits on c.
+//
+// benbjohnson/clock's Timer and Ticker are concrete types which can only be
+// created by that package, so they can't be backed by an arbitrary clockwork
+// Clock. For a real clock, AfterFunc, Timer and Ticker use the real time
+// package as bjclock.New does. For a fake clock they panic: use After, Sleep,
+// Tick, WithDeadline and WithTimeout, which are fully supported, or port the
+// code under test to clockwork.
+func AsBenbjohnson(c clockwork.Clock) bjclock.Clock {
+	return &toBenbjohnson{c: c, fake: clockwork.IsFake(c)}
+}
+
+type toBenbjohnson struct {
+	c    clockwork.Clock
+	fake bool
+}
+
+func (a *toBenbjohnson) After(d time.Duration) <-chan time.Time { return a.c.After(d) }
+
+func (a *toBenbjohnson) AfterFunc(d time.Duration, f func()) *bjclock.Timer {
+	a.mustBeReal("AfterFunc")
+	return bjclock.New().AfterFunc(d, f)
+}
+
+func (a *toBenbjohnson) Now() time.Time { return a.c.Now() }
+
+func (a *toBenbjohnson) Since(t time.Time) time.Duration { return a.c.Since(t) }
+
+func (a *toBenbjohnson) Until(t time.Time) time.Duration { return t.Sub(a.c.Now()) }
+
+func (a *toBenbjohnson) Sleep(d time.Duration) { a.c.Sleep(d) }
+
+// Tick is like time.Tick: the underlying Ticker can never be stopped.
+func (a *toBenbjohnson) Tick(d time.Duration) <-chan time.Time {
+	if d <= 0 {
+		return nil
+	}
+	return a.c.NewTicker(d).Chan()
+}
+
+func (a *toBenbjohnson) Ticker(d time.Duration) *bjclock.Ticker {
+	a.mustBeReal("Ticker")
+	return bjclock.New().Ticker(d)
+}
+
+func (a *toBenbjohnson) Timer(d time.Duration) *bjclock.Timer {
+	a.mustBeReal("Timer")
+	return bjclock.New().Timer(d)
+}
+
+func (a *toBenbjohnson) WithDeadline(parent context.Context, d time.Time) (context.Context, context.CancelFunc) {
+	if cur, ok := parent.Deadline(); ok && !cur.After(d) {
+		// The parent's deadline is no later than the new one.
+		return context.WithCancel(parent)
+	}
+	ctx, cancel := context.WithCancel(parent)
+	dc := &deadlineCtx{Context: ctx, cancelCtx: cancel, deadline: d}
+	t := a.c.AfterFunc(d.Sub(a.c.Now()), func() { dc.cancel(context.DeadlineExceeded) })
+	return dc, func() {
+		t.Stop()
+		dc.cancel(context.Canceled)
+	}
+}
+
+func (a *toBenbjohnson) WithTimeout(parent context.Context, t time.Duration) (context.Context, context.CancelFunc) {
+	return a.WithDeadline(parent, a.c.Now().Add(t))
+}
+
+func (a *toBenbjohnson) mustBeReal(method string) {
+	if a.fake {
+		panic("clockwork/benbjohnson: " + method + " is not supported for a fake clock")
+	}
+}
+
+// deadlineCtx is a context which is cancelled when a deadline on a clockwork
+// Clock is reached, reporting context.DeadlineExceeded.
+type deadlineCtx struct {
+	context.Context
+	cancelCtx context.CancelFunc
+	deadline  time.Time
+
+	l   sync.Mutex
+	err error // set by the first call to cancel
+}
+
+func (dc *deadlineCtx) Deadline() (time.Time, bool) {
+	return dc.deadline, true
+}
+
+func (dc *deadlineCtx) Err() error {
+	dc.l.Lock()
+	defer dc.l.Unlock()
+	if dc.err != nil {
+		return dc.err
+	}
+	return dc.Context.Err()
+}
+
+func (dc *deadlineCtx) cancel(err error) {
+	dc.l.Lock()
+	// If the parent has already been cancelled, its error stands.
+	if dc.err == nil && dc.Context.Err() == nil {
+		dc.err = err
+	}
+	dc.l.Unlock()
+	dc.cancelCtx()
+}
+
+// FromBenbjohnson returns a clockwork Clock backed by c. Timers returned by
+// the Clock don't expose an underlying *time.Timer, so their T method returns
+// nil.
+func FromBenbjohnson(c bjclock.Clock) clockwork.Clock {
+	return &fromBenbjohnson{c: c}
+}
+
+type fromBenbjohnson struct {
+	c bjclock.Clock
+}
+
+func (a *fromBenbjohnson) After(d time.Duration) <-chan time.Time { return a.c.After(d) }
+
+func (a *fromBenbjohnson) Sleep(d time.Duration) { a.c.Sleep(d) }
+
+func (a *fromBenbjohnson) SleepUntil(t time.Time) { a.c.Sleep(a.c.Until(t)) }
+
+func (a *fromBenbjohnson) Now() time.Time { return a.c.Now() }
+
+func (a *fromBenbjohnson) NowUnixNano() int64 { return a.c.Now().UnixNano() }
+
+func (a *fromBenbjohnson) NowUnixMilli() int64 {
+	now := a.c.Now()
+	return now.Unix()*1e3 + int64(now.Nanosecond())/1e6
+}
+
+func (a *fromBenbjohnson) NowUnix() int64 { return a.c.Now().Unix() }
+
+func (a *fromBenbjohnson) Since(t time.Time) time.Duration { return a.c.Since(t) }
+
+func (a *fromBenbjohnson) Until(t time.Time) time.Duration { return a.c.Until(t) }
+
+func (a *fromBenbjohnson) NewTicker(d time.Duration) clockwork.Ticker {
+	return &ticker{a.c.Ticker(d)}
+}
+
+func (a *fromBenbjohnson) NewTickerImmediate(d time.Duration) clockwork.Ticker {
+	return newImmediateTicker(a.c.Ticker(d), a.c.Now())
+}
+
+func (a *fromBenbjohnson) TryNewTicker(d time.Duration) (clockwork.Ticker, error) {
+	if d <= 0 {
+		return nil, clockwork.ErrNonPositiveInterval
+	}
+	return a.NewTicker(d), nil
+}
+
+func (a *fromBenbjohnson) NewBurstTicker(d time.Duration, n int) clockwork.Ticker {
+	if n <= 0 {
+		panic(clockwork.ErrNonPositiveBurst)
+	}
+	return newBurstTicker(a.c.Ticker(d), n)
+}
+
+func (a *fromBenbjohnson) NewBufferedTicker(d time.Duration, buf int) clockwork.Ticker {
+	if buf <= 0 {
+		panic(clockwork.ErrNonPositiveBuffer)
+	}
+	return newBufferedTicker(a.c.Ticker(d), buf)
+}
+
+func (a *fromBenbjohnson) NewAlignedTicker(d time.Duration) clockwork.Ticker {
+	if d <= 0 {
+		panic(clockwork.ErrNonPositiveInterval)
+	}
+	now := a.c.Now()
+	return newAlignedTicker(a.c, a.c.Timer(now.Truncate(d).Add(d).Sub(now)), d)
+}
+
+func (a *fromBenbjohnson) NewSkewedTicker(d time.Duration, skew func(n int) time.Duration) clockwork.Ticker {
+	if d <= 0 {
+		panic(clockwork.ErrNonPositiveInterval)
+	}
+	if skew == nil {
+		return a.NewTicker(d)
+	}
+	return newSkewedTicker(a.c, d, skew)
+}
+
+func (a *fromBenbjohnson) AfterString(s string) (<-chan time.Time, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return nil, err
+	}
+	return a.After(d), nil
+}
+
+func (a *fromBenbjohnson) NewTimerString(s string) (clockwork.Timer, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return nil, err
+	}
+	return a.NewTimer(d), nil
+}
+
+func (a *fromBenbjohnson) NewTimer(d time.Duration) clockwork.Timer {
+	return &timer{a.c.Timer(d)}
+}
+
+func (a *fromBenbjohnson) NewTimerAt(t time.Time) clockwork.Timer {
+	return a.NewTimer(a.c.Until(t))
+}
+
+func (a *fromBenbjohnson) AfterAt(t time.Time) <-chan time.Time {
+	return a.c.After(a.c.Until(t))
+}
+
+func (a *fromBenbjohnson) AfterFunc(d time.Duration, f func()) clockwork.Timer {
+	return &timer{a.c.AfterFunc(d, f)}
+}
+
+func (a *fromBenbjohnson) AfterFuncDone(d time.Duration, f func()) (clockwork.Timer, <-chan struct{}) {
+	dt := &doneTimer{done: make(chan struct{})}
+	dt.Timer = a.AfterFunc(d, func() {
+		defer dt.close()
+		f()
+	})
+	return dt, dt.done
+}
+
+func (a *fromBenbjohnson) NextMidnight() time.Time {
+	now := a.c.Now()
+	y, m, d := now.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, now.Location())
+}
+
+func (a *fromBenbjohnson) NowTruncated(d time.Duration) time.Time {
+	return a.c.Now().Truncate(d)
+}
+
+func (a *fromBenbjohnson) NowRounded(d time.Duration) time.Time {
+	return a.c.Now().Round(d)
+}
+
+func (a *fromBenbjohnson) DeadlineContext(parent context.Context, t time.Time) (context.Context, context.CancelFunc) {
+	return a.c.WithDeadline(parent, t)
+}
+
+func (a *fromBenbjohnson) Date(year int, month time.Month, day, hour, min, sec, nsec int) time.Time {
+	return time.Date(year, month, day, hour, min, sec, nsec, a.c.Now().Location())
+}
+
+type timer struct{ t *bjclock.Timer }
+
+func (t *timer) C() <-chan time.Time        { return t.t.C }
+func (t *timer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+func (t *timer) Stop() bool                 { return t.t.Stop() }
+func (t *timer) T() *time.Timer             { return nil }
+
+// Deadline is not supported: a bjclock.Timer doesn't expose its expiry.
+func (t *timer) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+type ticker struct{ t *bjclock.Ticker }
+
+func (t *ticker) Chan() <-chan time.Time { return t.t.C }
+func (t *ticker) Stop()                  { t.t.Stop() }
+
+// forwardingTicker is a Ticker whose ticks are forwarded to c by a goroutine
+// reading from a bjclock.Ticker or bjclock.Timer, dropping any which find c
+// full. The variants of NewTicker differ only in what that goroutine does.
+type forwardingTicker struct {
+	c      chan time.Time
+	onStop func() // stops the source straight away, if set
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+func newForwardingTicker(buf int, onStop func()) *forwardingTicker {
+	return &forwardingTicker{
+		c:      make(chan time.Time, buf),
+		onStop: onStop,
+		stop:   make(chan struct{}),
+	}
+}
+
+func (ft *forwardingTicker) Chan() <-chan time.Time { return ft.c }
+
+func (ft *forwardingTicker) Stop() {
+	if ft.onStop != nil {
+		ft.onStop()
+	}
+	ft.stopOnce.Do(func() { close(ft.stop) })
+}
+
+// receive waits for a tick from src. It reports false if the ticker is
+// stopped first.
+func (ft *forwardingTicker) receive(src <-chan time.Time) (time.Time, bool) {
+	select {
+	case <-ft.stop:
+		return time.Time{}, false
+	case tick := <-src:
+		return tick, true
+	}
+}
+
+// offer sends tick on c unless c is full, and reports whether it did.
+func (ft *forwardingTicker) offer(tick time.Time) bool {
+	select {
+	case ft.c <- tick:
+		return true
+	default:
+		return false
+	}
+}
+
+// forward starts a goroutine which forwards every tick from src until the
+// ticker is stopped.
+func (ft *forwardingTicker) forward(src <-chan time.Time) {
+	go func() {
+		for {
+			tick, ok := ft.receive(src)
+			if !ok {
+				return
+			}
+			ft.offer(tick)
+		}
+	}()
+}
+
+// newImmediateTicker wraps t, delivering an additional tick when it is
+// created.
+func newImmediateTicker(t *bjclock.Ticker, now time.Time) *forwardingTicker {
+	ft := newForwardingTicker(1, t.Stop)
+	ft.c <- now
+	ft.forward(t.C)
+	return ft
+}
+
+// newBurstTicker wraps t, forwarding n ticks and then stopping it.
+func newBurstTicker(t *bjclock.Ticker, n int) *forwardingTicker {
+	ft := newForwardingTicker(1, t.Stop)
+	go func() {
+		defer t.Stop()
+		for n > 0 {
+			tick, ok := ft.receive(t.C)
+			if !ok {
+				return
+			}
+			if ft.offer(tick) {
+				n--
+			}
+		}
+	}()
+	return ft
+}
+
+// newBufferedTicker wraps t, forwarding its ticks to a channel holding up to
+// buf of them.
+func newBufferedTicker(t *bjclock.Ticker, buf int) *forwardingTicker {
+	ft := newForwardingTicker(buf, t.Stop)
+	ft.forward(t.C)
+	return ft
+}
+
+// newAlignedTicker waits for first to reach the first boundary, then ticks at
+// it and every period after it, using a bjclock.Ticker started there.
+func newAlignedTicker(c bjclock.Clock, first *bjclock.Timer, period time.Duration) *forwardingTicker {
+	ft := newForwardingTicker(1, nil)
+	go func() {
+		defer first.Stop()
+		tick, ok := ft.receive(first.C)
+		if !ok {
+			return
+		}
+		t := c.Ticker(period)
+		defer t.Stop()
+		for ok {
+			ft.offer(tick)
+			tick, ok = ft.receive(t.C)
+		}
+	}()
+	return ft
+}
+
+// newSkewedTicker ticks at the times of a skewed ticker, using a bjclock.Timer
+// re-armed for each tick. See clockwork.Clock.NewSkewedTicker.
+func newSkewedTicker(c bjclock.Clock, period time.Duration, skew func(n int) time.Duration) *forwardingTicker {
+	ft := newForwardingTicker(1, nil)
+	start := c.Now()
+	go func() {
+		var t *bjclock.Timer
+		for n := 1; ; n++ {
+			d := c.Until(start.Add(time.Duration(n)*period + skew(n)))
+			if d <= 0 {
+				continue
+			}
+			if t == nil {
+				t = c.Timer(d)
+				defer t.Stop()
+			} else {
+				t.Reset(d)
+			}
+			tick, ok := ft.receive(t.C)
+			if !ok {
+				return
+			}
+			ft.offer(tick)
+		}
+	}()
+	return ft
+}
+
+// doneTimer wraps a Timer created by AfterFunc, closing done once the function
+// has returned or the Timer has been stopped before it ran.
+type doneTimer struct {
+	clockwork.Timer
+
+	once sync.Once
+	done chan struct{}
+}
+
+func (dt *doneTimer) close() {
+	dt.once.Do(func() { close(dt.done) })
+}
+
+func (dt *doneTimer) Stop() bool {
+	stopped := dt.Timer.Stop()
+	if stopped {
+		dt.close()
+	}
+	return stopped
+}
@@ -0,0 +1,140 @@
+package benbjohnson
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bjclock "github.com/benbjohnson/clock"
+	"github.com/jangala-dev/clockwork"
+)
+
+func TestAsBenbjohnsonFake(t *testing.T) {
+	t.Parallel()
+	fc := clockwork.NewFakeClock()
+	c := AsBenbjohnson(fc)
+
+	if got, want := c.Now(), fc.Now(); !got.Equal(want) {
+		t.Errorf("Now() = %v, want %v", got, want)
+	}
+	if got := c.Until(fc.Now().Add(time.Minute)); got != time.Minute {
+		t.Errorf("Until() = %v, want 1m", got)
+	}
+
+	after := c.After(time.Second)
+	ctx, cancel := c.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(fc.Now().Add(2*time.Second)) {
+		t.Errorf("Deadline() = %v, %v, want %v, true", deadline, ok, fc.Now().Add(2*time.Second))
+	}
+	fc.BlockUntil(2)
+
+	fc.Advance(time.Second)
+	select {
+	case <-after:
+	case <-time.After(time.Second):
+		t.Fatalf("After did not fire")
+	}
+	if err := ctx.Err(); err != nil {
+		t.Errorf("context ended early: %v", err)
+	}
+
+	fc.Advance(time.Second)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("context did not time out")
+	}
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Errorf("Err() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestAsBenbjohnsonCancel(t *testing.T) {
+	t.Parallel()
+	fc := clockwork.NewFakeClock()
+	c := AsBenbjohnson(fc)
+
+	ctx, cancel := c.WithTimeout(context.Background(), time.Second)
+	cancel()
+	<-ctx.Done()
+	if err := ctx.Err(); err != context.Canceled {
+		t.Errorf("Err() = %v, want %v", err, context.Canceled)
+	}
+	// Cancelling releases the clock's sleeper.
+	fc.BlockUntil(0)
+}
+
+func TestAsBenbjohnsonFakeTimerPanics(t *testing.T) {
+	t.Parallel()
+	c := AsBenbjohnson(clockwork.NewFakeClock())
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Timer did not panic for a fake clock")
+		}
+	}()
+	c.Timer(time.Second)
+}
+
+func TestFromBenbjohnson(t *testing.T) {
+	t.Parallel()
+	mock := bjclock.NewMock()
+	c := FromBenbjohnson(mock)
+
+	if got, want := c.Now(), mock.Now(); !got.Equal(want) {
+		t.Errorf("Now() = %v, want %v", got, want)
+	}
+
+	timer := c.NewTimer(time.Second)
+	if timer.T() != nil {
+		t.Errorf("T() = %v, want nil", timer.T())
+	}
+	ran := make(chan struct{})
+	_, done := c.AfterFuncDone(2*time.Second, func() { close(ran) })
+	stopped := c.NewTimerAt(mock.Now().Add(time.Second))
+	if !stopped.Stop() {
+		t.Errorf("Stop() = false for a pending timer")
+	}
+
+	mock.Add(time.Second)
+	select {
+	case <-timer.C():
+	default:
+		t.Errorf("timer did not fire")
+	}
+	select {
+	case <-stopped.C():
+		t.Errorf("stopped timer fired")
+	default:
+	}
+
+	mock.Add(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("AfterFuncDone did not report completion")
+	}
+	<-ran
+}
+
+func TestFromBenbjohnsonTickerImmediate(t *testing.T) {
+	t.Parallel()
+	mock := bjclock.NewMock()
+	c := FromBenbjohnson(mock)
+	start := mock.Now()
+
+	ticker := c.NewTickerImmediate(time.Second)
+	defer ticker.Stop()
+	if tick := <-ticker.Chan(); !tick.Equal(start) {
+		t.Errorf("immediate tick at %v, want %v", tick, start)
+	}
+	mock.Add(time.Second)
+	select {
+	case tick := <-ticker.Chan():
+		if want := start.Add(time.Second); !tick.Equal(want) {
+			t.Errorf("tick at %v, want %v", tick, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected a periodic tick")
+	}
+}
@@ -0,0 +1,11 @@
+module github.com/jangala-dev/clockwork/benbjohnson
+
+go 1.13
+
+require (
+	github.com/benbjohnson/clock v1.3.5
+	github.com/jangala-dev/clockwork v0.0.0-20261016155423-1919f13d58d0
+)
+
+// Development in this repository uses the root module as checked out.
+replace github.com/jangala-dev/clockwork => ../
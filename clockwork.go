@@ -1,7 +1,12 @@
 package clockwork
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -12,21 +17,154 @@ import (
 type Clock interface {
 	After(d time.Duration) <-chan time.Time
 	Sleep(d time.Duration)
+	// SleepUntil blocks until Now() is at or after t, returning immediately
+	// if it already is.
+	SleepUntil(t time.Time)
 	Now() time.Time
+	// NowUnixNano, NowUnixMilli and NowUnix return Now() as the number of
+	// nanoseconds, milliseconds or seconds since the Unix epoch, like the
+	// corresponding methods of time.Time.
+	NowUnixNano() int64
+	NowUnixMilli() int64
+	NowUnix() int64
+	// Since returns the time elapsed since t, measured from Now(). Like
+	// time.Time.Sub, it saturates at the largest or smallest time.Duration
+	// when the gap is too large to represent, rather than overflowing, and
+	// it uses monotonic clock readings only if both times carry one. Times
+	// from a FakeClock never do, so mixing them with a real clock's times
+	// compares wall clock readings. See SinceMonotonic.
 	Since(t time.Time) time.Duration
+	// Until returns the duration until t, measured from Now(). It saturates
+	// in the same way as Since.
+	Until(t time.Time) time.Duration
 	NewTicker(d time.Duration) Ticker
+	// NewTickerImmediate is like NewTicker, but the returned Ticker also
+	// delivers a tick as soon as it is created.
+	NewTickerImmediate(d time.Duration) Ticker
+	// TryNewTicker is like NewTicker, but returns an error rather than
+	// panicking if d is not positive.
+	TryNewTicker(d time.Duration) (Ticker, error)
+	// NewBurstTicker is like NewTicker, but the returned Ticker stops itself
+	// once it has delivered n ticks. It panics if n is not positive.
+	NewBurstTicker(d time.Duration, n int) Ticker
+	// NewBufferedTicker is like NewTicker, but the returned Ticker's channel
+	// holds up to buf ticks, so a slow consumer only loses ticks once buf are
+	// waiting. This differs from time.Ticker, which buffers a single tick, and
+	// is intended for tests which check every tick. It panics if buf is not
+	// positive.
+	NewBufferedTicker(d time.Duration, buf int) Ticker
+	// NewSkewedTicker is like NewTicker, but the nth tick, counting from 1,
+	// is due at n*d + skew(n) after the ticker is created, rather than n*d,
+	// to test code which assumes perfectly periodic ticks against clock skew.
+	// Ticks whose time has already passed when the previous tick is delivered
+	// are skipped, as are those which would be due before the ticker is
+	// created. A nil skew means no skew.
+	NewSkewedTicker(d time.Duration, skew func(n int) time.Duration) Ticker
+	// NewAlignedTicker is like NewTicker, but ticks on the boundaries where
+	// the time is a multiple of d, as defined by time.Time.Truncate: first at
+	// Now().Truncate(d).Add(d), and then every d.
+	NewAlignedTicker(d time.Duration) Ticker
 	NewTimer(d time.Duration) Timer
+	// NewTimerAt is like NewTimer, but fires when the clock reaches t rather
+	// than after a duration. If t is not in the future it fires immediately.
+	NewTimerAt(t time.Time) Timer
+	// AfterAt is like After, but fires when the clock reaches t.
+	AfterAt(t time.Time) <-chan time.Time
+	// AfterString and NewTimerString are like After and NewTimer, but take
+	// the duration as a string to be parsed by time.ParseDuration, such as
+	// "1h30m" from a configuration file. They return the parse error, if any,
+	// without scheduling anything.
+	AfterString(s string) (<-chan time.Time, error)
+	NewTimerString(s string) (Timer, error)
 	AfterFunc(d time.Duration, f func()) Timer
+	// AfterFuncDone is like AfterFunc, but also returns a channel which is
+	// closed once f has returned, or once the Timer is stopped before f runs.
+	AfterFuncDone(d time.Duration, f func()) (Timer, <-chan struct{})
+	// NextMidnight returns the next 00:00 after Now() in Now()'s location.
+	NextMidnight() time.Time
+	// NowTruncated returns Now().Truncate(d).
+	NowTruncated(d time.Duration) time.Time
+	// NowRounded returns Now().Round(d).
+	NowRounded(d time.Duration) time.Time
+	// DeadlineContext is like context.WithDeadline, but the returned context
+	// is done when the clock reaches t.
+	DeadlineContext(parent context.Context, t time.Time) (context.Context, context.CancelFunc)
+	// Date is like time.Date, but returns a time in Now()'s location.
+	Date(year int, month time.Month, day, hour, min, sec, nsec int) time.Time
 }
 
 // Timer provides an interface to a time.Timer which is testable.
 // See https://golang.org/pkg/time/#Timer for more details on how timers work.
 type Timer interface {
+	// C returns the channel on which the Timer delivers its time. As with
+	// time.AfterFunc, it is nil for a Timer created by AfterFunc.
 	C() <-chan time.Time
 	Reset(d time.Duration) bool
 	Stop() bool
 
 	T() *time.Timer // underlying *time.Timer (nil when using a FakeClock)
+
+	// Deadline returns the time at which the Timer will fire, if it is
+	// pending. It is only supported for timers created by a FakeClock, and
+	// always returns false for timers created by the real clock.
+	Deadline() (time.Time, bool)
+}
+
+// ResetDrained resets t to expire after d, using the idiom the real timers of
+// Go releases before 1.23 need: if t can't be stopped because it has already
+// fired, its value is discarded from its channel first, unless it has been
+// received, so that no value from the old expiry can be received after the
+// Reset. It reports whether t was active, like Reset. Nothing else should be
+// receiving from t's channel meanwhile.
+func ResetDrained(t Timer, d time.Duration) bool {
+	active := t.Stop()
+	if !active {
+		select {
+		case <-t.C():
+		default:
+		}
+	}
+	t.Reset(d)
+	return active
+}
+
+// PausableTimer is a Timer which can be paused and resumed, for testing code
+// which suspends its timers, for example during a maintenance window. The
+// timers created by a FakeClock's NewTimer, NewTimerAt, AfterFunc and
+// variants implement it; those created by the real clock don't.
+type PausableTimer interface {
+	Timer
+	// Pause stops the Timer, remembering how long it had left to wait, and
+	// reports whether it was pending. A paused Timer doesn't fire however
+	// far its clock is advanced, and Deadline reports no deadline for it.
+	// Stop and Reset treat it as pending.
+	Pause() bool
+	// Resume re-arms a paused Timer to fire once the time it had left has
+	// passed, measured from the clock's time when Resume is called. It
+	// reports whether the Timer was paused.
+	Resume() bool
+}
+
+// IsActive reports whether t is still pending, i.e. whether it will fire if
+// its clock is advanced far enough. A paused Timer counts as pending. Timers
+// returned by AfterFuncDone and NewDeadlineTimer are looked through to the
+// Timer they wrap. IsActive returns false for timers created by the real
+// clock, whose state can't be inspected.
+func IsActive(t Timer) bool {
+	for {
+		switch w := t.(type) {
+		case *doneTimer:
+			t = w.Timer
+		case *deadlineTimer:
+			t = w.Timer
+		case *sleeper:
+			w.fc.l.Lock()
+			defer w.fc.l.Unlock()
+			return w.paused || atomic.LoadUint32(&w.done) == 0
+		default:
+			return false
+		}
+	}
 }
 
 // FakeClock provides an interface for a clock which can be
@@ -34,15 +172,192 @@ type Timer interface {
 type FakeClock interface {
 	Clock
 	// Advance advances the FakeClock to a new point in time, ensuring any existing
-	// sleepers are notified appropriately before returning.
+	// sleepers are notified appropriately before returning. Sleepers are
+	// notified in deadline order, and sleepers with identical deadlines are
+	// notified in the order they were armed: created, or last Reset or
+	// Resumed. With WithMaxFiresPerAdvance, sleepers held over from the
+	// previous move are notified first. A sleeper is notified once the clock
+	// reaches its deadline, including when it lands on it exactly.
 	Advance(d time.Duration)
+	// AdvanceAndWait is like Advance, but then waits for any functions
+	// scheduled with AfterFunc which became due to return.
+	AdvanceAndWait(d time.Duration)
+	// AdvanceSafe is like Advance, but runs the functions scheduled with
+	// AfterFunc which became due on the calling goroutine, one after another,
+	// before it returns. If any of them panics, the panic is recovered, the
+	// remaining functions still run, and AdvanceSafe returns a
+	// *CallbackPanicError for the first. With WithDeferredCallbacks or
+	// WithCallbackRunner, functions are handed over as usual instead.
+	AdvanceSafe(d time.Duration) error
+	// AdvanceAndYield is like Advance, but then yields the processor a few
+	// times so that functions scheduled with AfterFunc which became due get
+	// a chance to run. Unlike AdvanceAndWait, it doesn't wait for them to
+	// return. See WithYields.
+	AdvanceAndYield(d time.Duration)
+	// AdvanceUntil repeatedly advances the FakeClock by step, notifying
+	// sleepers after each step, until pred returns true for the current time.
+	// It returns an error if pred is still false after maxSteps steps.
+	AdvanceUntil(pred func(time.Time) bool, step time.Duration, maxSteps int) error
+	// AdvanceSteps advances the FakeClock from one scheduled deadline to the
+	// next, n times, notifying the sleepers due at each before moving on. It
+	// returns the number of steps taken, which is less than n if it runs out
+	// of sleepers.
+	AdvanceSteps(n int) int
+	// AdvanceSequence advances the FakeClock by each of steps in turn,
+	// notifying sleepers after each. If onStep is not nil it is called after
+	// each step with the step's index and the clock's new time, so a test can
+	// make assertions between steps.
+	AdvanceSequence(onStep func(i int, now time.Time), steps ...time.Duration)
 	// BlockUntil will block until the FakeClock has the given number of
 	// sleepers (callers of Sleep or After).
 	BlockUntil(n int)
+	// Blockers returns the sleeper counts which the callers currently
+	// blocked in BlockUntil are waiting for, in the order they called it. A
+	// test stuck in BlockUntil is often waiting for a count which will never
+	// be reached.
+	Blockers() []int
+	// LastBlockDuration returns how long, in real time, the most recent
+	// call to BlockUntil to return was blocked for, which is zero if it
+	// didn't need to wait. It helps to find where a slow test waits for the
+	// code under test.
+	LastBlockDuration() time.Duration
+	// BlockUntilBlocked blocks until exactly n goroutines are blocked in
+	// Sleep or SleepUntil. Unlike BlockUntil, it does not count sleepers whose channel
+	// nobody may be waiting on yet, such as those created by After.
+	BlockUntilBlocked(n int)
 	// Set sets the FakeClock to a new point in time, ensuring channels from any
 	// existing sleepers (callers of Sleep or After) are notified appropriately
-	// before returning.
+	// before returning. As with Advance, sleepers whose deadline is at or before
+	// t are notified; in particular Set(Now()) leaves the time unchanged but
+	// notifies any sleeper due at the current instant.
 	Set(t time.Time)
+	// Jump moves the FakeClock's time by d, forward or backward, as a
+	// discontinuity such as an NTP correction of the wall clock, rather than
+	// the passage of time. Unlike Advance it fires no sleepers: every pending
+	// sleeper's deadline moves by d too, so each still fires after the same
+	// amount of Advance as before, as timers running on a monotonic clock
+	// would.
+	Jump(d time.Duration)
+	// AdvanceMonotonic, SetWall and Monotonic model the FakeClock's time as
+	// two timelines, like the runtime's clock: a monotonic one, which only
+	// moves forward and drives every sleeper, and a wall clock, which Now
+	// reports. AdvanceMonotonic is Advance, and panics if d is negative.
+	// SetWall is Jump to t, moving only the wall clock. Monotonic returns the
+	// monotonic time elapsed since the FakeClock was created, which Advance,
+	// Set forward and their variants add to, and Jump, SetWall and Set
+	// backward don't.
+	//
+	// The times returned by Now carry no monotonic reading, as only the
+	// runtime can create one, so the monotonic timeline must be read with
+	// Monotonic rather than by subtracting times.
+	AdvanceMonotonic(d time.Duration)
+	SetWall(t time.Time)
+	Monotonic() time.Duration
+	// OnFire adds f to the functions called each time t fires, with the time
+	// it fired at, in addition to whatever t does when it fires. The
+	// functions are called in the order they were added, on the goroutine
+	// which moved the clock, before Advance or Set returns, and keep being
+	// called if t is Reset. For a timer created by AfterFunc, they are called
+	// once its function has been started, or queued with
+	// WithDeferredCallbacks, not after it has returned. OnFire
+	// panics if t was not created by this FakeClock.
+	OnFire(t Timer, f func(time.Time))
+	// WaitTick advances the FakeClock to the next tick of tk, which must be a
+	// running ticker created by NewTicker or one of its variants on this
+	// FakeClock, and returns the tick, for driving a periodic loop one
+	// iteration at a time. Any ticks still unread on tk's channel are
+	// discarded first, so the tick returned is always the new one; nothing
+	// else should be receiving from tk meanwhile. WaitTick panics if tk is a
+	// step ticker or a wrapper, was created elsewhere or has stopped, or if
+	// the tick isn't delivered by the Advance, as when WithMaxFiresPerAdvance
+	// defers it.
+	WaitTick(tk Ticker) time.Time
+	// AdvanceToNextTick is like WaitTick, but leaves the tick on tk's
+	// channel rather than receiving it. It moves the FakeClock exactly to the
+	// tick's time, so only the sleepers due by then fire, however many other
+	// timers are pending.
+	AdvanceToNextTick(tk Ticker)
+	// OnNextAdvance arranges for f to be called once, at the end of the next
+	// Advance, Set or one of their variants, however far it moves the
+	// FakeClock, with the times it moved from and to. It is called on the
+	// goroutine which moved the clock, after the sleepers which became due
+	// have fired, for injecting a fault into a test when time next passes.
+	// Functions added before the same move are called in the order they were
+	// added.
+	OnNextAdvance(f func(from, to time.Time))
+	// NewStepTicker returns a Ticker which ticks once each time the FakeClock
+	// is moved by Advance, Set or their variants, however far it moves, for
+	// simulations driven frame by frame. Ticks carry the clock's new time.
+	NewStepTicker() Ticker
+	// TickerOverruns returns the number of ticks discarded because the
+	// ticker's previous tick was still unread. It is only counted when the
+	// FakeClock was created using WithStrictTickers, and is zero otherwise.
+	TickerOverruns() int
+	// StaleResets returns the number of times a timer created by the
+	// FakeClock was Reset while a value from its earlier expiry was still
+	// unread on its channel. The FakeClock discards such values, but with
+	// the real timers of Go releases before 1.23 the value would have been
+	// received as though from the new expiry, so each one is likely a
+	// missing drain in the code under test; see ResetDrained. They are not
+	// counted when the FakeClock was created using WithGo123Timers, whose
+	// semantics make resetting without draining safe.
+	StaleResets() int
+	// ActiveTickers returns the number of tickers created by the FakeClock
+	// which are still running: those which haven't been stopped, and, for
+	// burst tickers, haven't delivered all their ticks.
+	ActiveTickers() int
+	// TimeRange returns the earliest and latest deadlines among the pending
+	// sleepers, including those of running tickers' next ticks, to show the
+	// span of scheduled work and help decide how far to advance. ok is false
+	// when there are no pending sleepers.
+	TimeRange() (earliest, latest time.Time, ok bool)
+	// Stats returns cumulative counts of the timers and tickers created,
+	// fired and stopped, and of the times the FakeClock was advanced.
+	Stats() ClockStats
+	// RunPendingCallbacks runs the functions scheduled with AfterFunc which
+	// have become due, on the calling goroutine and in the order they became
+	// due, and returns how many ran. It is only needed when the FakeClock was
+	// created using WithDeferredCallbacks; otherwise functions run as soon as
+	// they are due, and RunPendingCallbacks returns zero.
+	RunPendingCallbacks() int
+	// Scope runs f, then restores the FakeClock's time to what it was before
+	// f was called, and stops any sleepers created during f which are still
+	// pending. It acts as a savepoint for test helpers which need to move the
+	// clock temporarily.
+	Scope(f func())
+	// Snapshot captures the FakeClock's time and the deadlines of its pending
+	// sleepers, so that Restore can return to them later.
+	Snapshot() Snapshot
+	// Restore returns the FakeClock to the time and pending sleepers captured
+	// by Snapshot, letting a test explore several scenarios from a common
+	// checkpoint.
+	Restore(snap Snapshot)
+	// Clone returns a new FakeClock at the FakeClock's current time, for
+	// forking a simulation into branches from a common point. Only the time
+	// is copied: the clone has no sleepers, blockers or tickers, and none of
+	// the FakeClock's options, only opts. Advancing either clock doesn't move
+	// the other.
+	Clone(opts ...Option) FakeClock
+	// PendingSleepers describes each pending sleeper, by its deadline, the
+	// time left until it, and how it was created, in no particular order. It
+	// returns nil when there are none. See AssertNoPending.
+	PendingSleepers() []string
+	// EventLog returns the events recorded so far, if the FakeClock was
+	// created using WithEventLog.
+	EventLog() []Event
+	// Close stops every running ticker created by the FakeClock, and then
+	// every pending sleeper, releasing any goroutines blocked in Sleep. It
+	// returns the number of each it stopped. It also stops the watchdog
+	// started by WithWatchdog for good: sleepers created after Close are not
+	// watched. Otherwise, unlike Stop, it leaves the FakeClock usable, so it
+	// suits the end of a test which may have left tickers running.
+	Close() (tickers, sleepers int)
+	// Stop discards all pending sleepers and releases any goroutines blocked
+	// in Sleep. Sleep returns immediately on a stopped FakeClock; channels
+	// returned by After and NewTimer for discarded sleepers never fire, while
+	// the done channels of discarded AfterFuncDone functions are closed. All
+	// tickers, including step tickers, are stopped.
+	Stop()
 }
 
 // NewRealClock returns a Clock which simply delegates calls to the actual time
@@ -54,16 +369,135 @@ func NewRealClock() Clock {
 // NewFakeClock returns a FakeClock implementation which can be
 // manually advanced through time for testing. The initial time of the
 // FakeClock will be an arbitrary non-zero time.
-func NewFakeClock() FakeClock {
+func NewFakeClock(opts ...Option) FakeClock {
+	return NewFakeClockWith(opts...)
+}
+
+// NewFakeClockWith returns a FakeClock configured by opts, which the other
+// constructors build on. It starts at the same arbitrary non-zero time as
+// NewFakeClock, unless WithStart is given, and in that time's location,
+// unless WithLocation is given. Later options override earlier ones.
+func NewFakeClockWith(opts ...Option) FakeClock {
+	fc := &fakeClock{}
 	// use a fixture that does not fulfill Time.IsZero()
-	return NewFakeClockAt(time.Date(1984, time.April, 4, 0, 0, 0, 0, time.UTC))
+	fc.setTime(time.Date(1984, time.April, 4, 0, 0, 0, 0, time.UTC))
+	for _, opt := range opts {
+		opt(fc)
+	}
+	if fc.location != nil {
+		fc.setTime(fc.time.In(fc.location))
+	}
+	if fc.watchdog > 0 {
+		// Started once every option has been applied.
+		fc.watchdogStop = make(chan struct{})
+		fc.watchdogExited = make(chan struct{})
+		go fc.runWatchdog()
+	}
+	return fc
 }
 
 // NewFakeClockAt returns a FakeClock initialised at the given time.Time.
-func NewFakeClockAt(t time.Time) FakeClock {
-	return &fakeClock{
-		time: t,
+func NewFakeClockAt(t time.Time, opts ...Option) FakeClock {
+	return NewFakeClockWith(append([]Option{WithStart(t)}, opts...)...)
+}
+
+// NewFakeClockAtEpoch returns a FakeClock initialised at the Unix epoch,
+// 1970-01-01 00:00:00 UTC.
+func NewFakeClockAtEpoch(opts ...Option) FakeClock {
+	return NewFakeClockAt(time.Unix(0, 0).UTC(), opts...)
+}
+
+// NewFakeClockNow returns a FakeClock initialised at the current real time.
+// The FakeClock does not move with the real clock after creation.
+func NewFakeClockNow(opts ...Option) FakeClock {
+	return NewFakeClockAt(time.Now(), opts...)
+}
+
+// ScheduledEvent is a function to run at a given time, for
+// NewFakeClockWithEvents.
+type ScheduledEvent struct {
+	At   time.Time
+	Func func()
+}
+
+// NewFakeClockWithEvents returns a FakeClock initialised at start, with an
+// AfterFunc timer for each of events. It is intended for replaying a captured
+// schedule: advancing the clock past the last event runs them all. Events
+// which are not after start run as soon as the clock is created.
+func NewFakeClockWithEvents(start time.Time, events []ScheduledEvent, opts ...Option) FakeClock {
+	fc := NewFakeClockAt(start, opts...)
+	for _, e := range events {
+		fc.AfterFunc(e.At.Sub(start), e.Func)
 	}
+	return fc
+}
+
+// FakeWrapper is implemented by Clock wrappers which are driven by a
+// FakeClock and want IsFake and AsFake to see through them.
+type FakeWrapper interface {
+	Clock
+	// Fake returns the FakeClock driving the wrapper.
+	Fake() FakeClock
+}
+
+// IsFake reports whether c is a FakeClock, or a wrapper around one which
+// implements FakeWrapper or Unwrap.
+func IsFake(c Clock) bool {
+	_, ok := AsFake(c)
+	return ok
+}
+
+// AsFake returns the FakeClock behind c, if c is a FakeClock or a wrapper
+// around one. Wrappers are seen through if they implement FakeWrapper, or
+// Unwrap as described for Base.
+func AsFake(c Clock) (FakeClock, bool) {
+	for c != nil {
+		switch w := c.(type) {
+		case *fakeClock:
+			return w, true
+		case FakeWrapper:
+			fc := w.Fake()
+			return fc, fc != nil
+		case unwrapper:
+			c = w.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// unwrapper is implemented by Clocks which decorate another Clock.
+type unwrapper interface {
+	Unwrap() Clock
+}
+
+// Base returns the Clock underlying c, by repeatedly unwrapping it. By
+// convention a Clock which decorates another, such as the clocks returned by
+// NewJitterClock, NewFreezableClock and NewScaledClock, has an Unwrap method
+// returning the Clock it decorates; Base unwraps until it reaches a Clock
+// without one. Decorators can be stacked, and Base and AsFake see through all
+// of them.
+func Base(c Clock) Clock {
+	for {
+		w, ok := c.(unwrapper)
+		if !ok {
+			return c
+		}
+		c = w.Unwrap()
+	}
+}
+
+// SinceMonotonic is like c.Since(t), but strips any monotonic clock reading
+// from both times first, so the result is the difference between their wall
+// clock readings whichever clocks they came from. c.Since uses monotonic
+// readings when both times have one, which only happens when both come from a
+// real clock, so the same pair of instants can give different results
+// depending on where they were read. SinceMonotonic gives consistent results
+// for libraries which pass timestamps between components using different
+// clocks, at the cost of being affected by wall clock changes on a real clock.
+func SinceMonotonic(c Clock, t time.Time) time.Duration {
+	return c.Now().Round(0).Sub(t.Round(0))
 }
 
 type realClock struct{}
@@ -76,26 +510,129 @@ func (rc *realClock) Sleep(d time.Duration) {
 	time.Sleep(d)
 }
 
+func (rc *realClock) SleepUntil(t time.Time) {
+	time.Sleep(time.Until(t))
+}
+
 func (rc *realClock) Now() time.Time {
 	return time.Now()
 }
 
+func (rc *realClock) NowUnixNano() int64 {
+	return time.Now().UnixNano()
+}
+
+func (rc *realClock) NowUnixMilli() int64 {
+	return unixMilli(time.Now())
+}
+
+func (rc *realClock) NowUnix() int64 {
+	return time.Now().Unix()
+}
+
 func (rc *realClock) Since(t time.Time) time.Duration {
 	return rc.Now().Sub(t)
 }
 
+func (rc *realClock) Until(t time.Time) time.Duration {
+	return t.Sub(rc.Now())
+}
+
+func (rc *realClock) NextMidnight() time.Time {
+	return nextMidnight(rc.Now())
+}
+
+func (rc *realClock) NowTruncated(d time.Duration) time.Time {
+	return rc.Now().Truncate(d)
+}
+
+func (rc *realClock) NowRounded(d time.Duration) time.Time {
+	return rc.Now().Round(d)
+}
+
+func (rc *realClock) Date(year int, month time.Month, day, hour, min, sec, nsec int) time.Time {
+	return time.Date(year, month, day, hour, min, sec, nsec, time.Local)
+}
+
 func (rc *realClock) NewTicker(d time.Duration) Ticker {
 	return &realTicker{time.NewTicker(d)}
 }
 
+func (rc *realClock) NewTickerImmediate(d time.Duration) Ticker {
+	return newRealImmediateTicker(time.NewTicker(d), rc.Now())
+}
+
+func (rc *realClock) TryNewTicker(d time.Duration) (Ticker, error) {
+	if d <= 0 {
+		return nil, ErrNonPositiveInterval
+	}
+	return rc.NewTicker(d), nil
+}
+
+func (rc *realClock) NewBurstTicker(d time.Duration, n int) Ticker {
+	if n <= 0 {
+		panic(ErrNonPositiveBurst)
+	}
+	return newRealBurstTicker(time.NewTicker(d), n)
+}
+
+func (rc *realClock) NewBufferedTicker(d time.Duration, buf int) Ticker {
+	if buf <= 0 {
+		panic(ErrNonPositiveBuffer)
+	}
+	return newRealBufferedTicker(time.NewTicker(d), buf)
+}
+
+func (rc *realClock) NewAlignedTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic(ErrNonPositiveInterval)
+	}
+	now := rc.Now()
+	return newRealAlignedTicker(time.NewTimer(now.Truncate(d).Add(d).Sub(now)), d)
+}
+
+func (rc *realClock) NewSkewedTicker(d time.Duration, skew func(n int) time.Duration) Ticker {
+	if d <= 0 {
+		panic(ErrNonPositiveInterval)
+	}
+	if skew == nil {
+		return rc.NewTicker(d)
+	}
+	return newRealSkewedTicker(rc.Now(), d, skew)
+}
+
 func (rc *realClock) NewTimer(d time.Duration) Timer {
 	return &realTimer{time.NewTimer(d)}
 }
 
+func (rc *realClock) NewTimerAt(t time.Time) Timer {
+	return rc.NewTimer(time.Until(t))
+}
+
+func (rc *realClock) AfterAt(t time.Time) <-chan time.Time {
+	return time.After(time.Until(t))
+}
+
+func (rc *realClock) AfterString(s string) (<-chan time.Time, error) {
+	return afterString(rc, s)
+}
+
+func (rc *realClock) NewTimerString(s string) (Timer, error) {
+	return newTimerString(rc, s)
+}
+
+func (rc *realClock) DeadlineContext(parent context.Context, t time.Time) (context.Context, context.CancelFunc) {
+	return context.WithDeadline(parent, t)
+}
+
 func (rc *realClock) AfterFunc(d time.Duration, f func()) Timer {
 	return &realTimer{time.AfterFunc(d, f)}
 }
 
+func (rc *realClock) AfterFuncDone(d time.Duration, f func()) (Timer, <-chan struct{}) {
+	return afterFuncDone(rc.AfterFunc, d, f)
+}
+
 type realTimer struct {
 	t *time.Timer
 }
@@ -104,6 +641,10 @@ func (rt *realTimer) C() <-chan time.Time { return rt.t.C }
 
 func (rt *realTimer) T() *time.Timer { return rt.t }
 
+// Deadline is not supported by the real clock: a time.Timer doesn't expose its
+// expiry.
+func (rt *realTimer) Deadline() (time.Time, bool) { return time.Time{}, false }
+
 func (rt *realTimer) Reset(d time.Duration) bool {
 	return rt.t.Reset(d)
 }
@@ -112,25 +653,146 @@ func (rt *realTimer) Stop() bool {
 	return rt.t.Stop()
 }
 
+// doneTimer wraps a Timer created by AfterFunc, closing done once the function
+// has returned or the Timer has been stopped before it ran. Once closed, done
+// stays closed even if the Timer is subsequently Reset.
+type doneTimer struct {
+	Timer
+
+	once sync.Once
+	done chan struct{}
+}
+
+// afterFuncDone implements AfterFuncDone in terms of an AfterFunc method.
+func afterFuncDone(afterFunc func(time.Duration, func()) Timer, d time.Duration, f func()) (Timer, <-chan struct{}) {
+	dt := &doneTimer{done: make(chan struct{})}
+	dt.Timer = afterFunc(d, func() {
+		defer dt.close()
+		f()
+	})
+	return dt, dt.done
+}
+
+func (dt *doneTimer) close() {
+	dt.once.Do(func() { close(dt.done) })
+}
+
+func (dt *doneTimer) Stop() bool {
+	stopped := dt.Timer.Stop()
+	if stopped {
+		dt.close()
+	}
+	return stopped
+}
+
+// fakeClock implements FakeClock. Its zero value is ready to use: it is a
+// fakeClock set to the zero time, with no options, which is what the
+// constructors build on.
 type fakeClock struct {
-	sleepers []*sleeper
-	blockers []*blocker
-	time     time.Time
+	// Accessed atomically; kept first to guarantee 64-bit alignment.
+	tickerOverruns uint64
+	staleResets    uint64
+	stats          stats
+
+	sleepers      []*sleeper
+	blockers      []*blocker
+	sleeping      int           // number of goroutines blocked in Sleep or SleepUntil
+	sleepBlockers []*blocker    // callers of BlockUntilBlocked, keyed by sleeping
+	tickers       []*fakeTicker // running tickers
+	stepTickers   []*stepTicker
+	onNextMove    []func(from, to time.Time) // added by OnNextAdvance
+	time          time.Time
+	now           atomic.Value  // holds time, for lock-free reads by Now
+	monotonic     time.Duration // moved only forward, by set
+	lastBlock     time.Duration // real time spent in the last BlockUntil
+	done          chan struct{} // closed by Stop; lazily created
+
+	maxAdvance        time.Duration                          // set by WithMaxAdvance; zero means no limit
+	strictTickers     bool                                   // set by WithStrictTickers
+	go123Timers       bool                                   // set by WithGo123Timers
+	eventLog          bool                                   // set by WithEventLog
+	resolution        time.Duration                          // set by WithResolution; zero means full precision
+	deferredCallbacks bool                                   // set by WithDeferredCallbacks
+	yields            int                                    // set by WithYields; zero means defaultYields
+	watchdog          time.Duration                          // set by WithWatchdog; zero means no watchdog
+	watchdogHandler   func(label string, deadline time.Time) // set by WithWatchdog
+	tickerLeaks       func(period time.Duration)             // set by WithTickerLeakWarnings
+	unbufferedTimers  bool                                   // set by WithUnbufferedTimers
+	callbackRunner    func(f func())                         // set by WithCallbackRunner
+	maxFires          int                                    // set by WithMaxFiresPerAdvance; zero means no limit
+	location          *time.Location                         // set by WithLocation, and applied after the other options
+
+	callbacksL sync.Mutex // Guards callbacks
+	callbacks  []func()   // due AfterFunc functions, when deferredCallbacks is set
+
+	eventsL sync.Mutex // Guards events
+	events  []Event
+
+	// watchdogStop is closed, once, by Close or Stop to stop the watchdog
+	// goroutine, which closes watchdogExited as it returns. Both are nil
+	// without WithWatchdog.
+	watchdogOnce   sync.Once
+	watchdogStop   chan struct{}
+	watchdogExited chan struct{}
 
 	l sync.RWMutex
 }
 
 // sleeper represents a waiting timer from NewTimer, Sleep, After, etc.
+// sleeperKind distinguishes the sleepers which Close, Scope and the stats
+// must treat specially from the rest.
+type sleeperKind uint8
+
+const (
+	timerSleeper  sleeperKind = iota // behind a Timer, After, AfterFunc or context
+	sleepSleeper                     // behind a goroutine blocked in Sleep or SleepUntil
+	tickerSleeper                    // due at a ticker's next tick
+)
+
 type sleeper struct {
-	until time.Time
-	l     sync.RWMutex // Guards until
+	until     time.Time
+	observers []func(time.Time) // added by OnFire
+	l         sync.RWMutex      // Guards until and observers
 
 	callback func(interface{}, time.Time)
 	arg      interface{}
+	fn       func()      // set for AfterFunc; called via a runner instead of callback
+	ticker   *fakeTicker // set for a ticker's sleeper, which ticks it instead of firing
+
+	ch    chan time.Time
+	done  uint32
+	fc    *fakeClock // needed for Reset()
+	label string     // describes how the sleeper was created
+	kind  sleeperKind
 
-	ch   chan time.Time
-	done uint32
-	fc   *fakeClock // needed for Reset()
+	// wake is closed, with fc.l held, to release the goroutine blocked in
+	// Sleep on a sleepSleeper once the sleeper is stopped by Close or Scope.
+	// It is nil for other kinds.
+	wake chan struct{}
+	// discarded is set for AfterFuncDone, to close its done channel when the
+	// clock discards the sleeper rather than it being stopped through its
+	// Timer.
+	discarded func()
+
+	// armed is the real time at which the sleeper was last added to the
+	// clock's sleepers, and reported whether the watchdog has reported it
+	// since. Both are only maintained with WithWatchdog, and guarded by fc.l.
+	armed    time.Time
+	reported bool
+
+	// paused is set by Pause, which removes the sleeper from the clock's
+	// sleepers while keeping left, the time it had left to wait, for Resume.
+	// Both are guarded by fc.l.
+	paused bool
+	left   time.Duration
+
+	// gen counts the times the sleeper's channel has been drained by Reset (or
+	// by Stop, with WithGo123Timers). It is changed with both fc.l and sendL
+	// held, and a firing sends only if gen is unchanged since it was claimed,
+	// so a value from before a Reset can't arrive after it.
+	sendL    sync.Mutex
+	gen      uint64
+	inflight *inflightSend // the latest send on an unbuffered channel; guarded by sendL
 }
 
 // blocker represents a caller of BlockUntil
@@ -139,24 +801,178 @@ type blocker struct {
 	ch    chan struct{}
 }
 
-func (s *sleeper) awaken(now time.Time) {
-	if atomic.CompareAndSwapUint32(&s.done, 0, 1) {
+// awaken fires a sleeper which has already been claimed (its done flag set
+// by the caller under fc.l, when its gen was gen). If the sleeper was created
+// by AfterFunc, its function is passed to run, with its label.
+func (s *sleeper) awaken(now time.Time, gen uint64, run func(fn func(), label string)) {
+	if s.fn != nil {
+		s.countTimer(&s.fc.stats.timersFired)
+		s.fc.logEvent(EventTimerFired, now, s.label)
+		switch {
+		case s.fc.deferredCallbacks:
+			s.fc.queueCallback(s.fn)
+		case s.fc.callbackRunner != nil:
+			s.fc.callbackRunner(s.fn)
+		default:
+			run(s.fn, s.label)
+		}
+		s.notifyObservers(now)
+		return
+	}
+	if s.send(now, gen) {
+		s.notifyObservers(now)
+	}
+}
+
+// send delivers the firing of a channel sleeper claimed when its gen was gen,
+// unless it is stale, and reports whether it did.
+func (s *sleeper) send(now time.Time, gen uint64) bool {
+	s.sendL.Lock()
+	defer s.sendL.Unlock()
+	if s.gen != gen {
+		// The channel was drained since this firing was claimed, so its value
+		// is stale.
+		return false
+	}
+	s.countTimer(&s.fc.stats.timersFired)
+	s.fc.logEvent(EventTimerFired, now, s.label)
+	if s.fc.unbufferedTimers && s.ch != nil {
+		s.sendAsync(now)
+	} else {
 		s.callback(s.arg, now)
 	}
+	return true
+}
+
+// inflightSend is a value being sent on an unbuffered timer channel by its
+// own goroutine. See WithUnbufferedTimers.
+type inflightSend struct {
+	cancel    chan struct{} // closed to abandon the send
+	exited    chan struct{} // closed when the goroutine returns
+	discarded bool          // whether the send was abandoned; set before exited is closed
+}
+
+// sendAsync starts a goroutine sending now on the sleeper's unbuffered
+// channel. The caller must hold sendL.
+func (s *sleeper) sendAsync(now time.Time) {
+	f := &inflightSend{
+		cancel: make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	s.inflight = f
+	go func() {
+		defer close(f.exited)
+		select {
+		case s.ch <- now:
+		case <-f.cancel:
+			f.discarded = true
+		}
+	}()
+}
+
+// notifyObservers calls the functions added by OnFire, in the order they
+// were added.
+func (s *sleeper) notifyObservers(now time.Time) {
+	s.l.RLock()
+	observers := s.observers
+	s.l.RUnlock()
+	for _, f := range observers {
+		f(now)
+	}
 }
 
 func (s *sleeper) C() <-chan time.Time { return s.ch }
 
 func (s *sleeper) T() *time.Timer { return nil }
 
+// Deadline returns the time at which the timer will fire, or false if it has
+// fired or been stopped.
+func (s *sleeper) Deadline() (time.Time, bool) {
+	s.fc.l.RLock()
+	defer s.fc.l.RUnlock()
+	if atomic.LoadUint32(&s.done) != 0 {
+		return time.Time{}, false
+	}
+	return s.Until(), true
+}
+
+// Reset changes the timer to expire after d, measured from the clock's time
+// when Reset is called. The timer is stopped and re-armed under a single hold
+// of the clock's lock, so a concurrent Advance happens either wholly before or
+// wholly after it, and can't shift the baseline d is measured from. However
+// often a timer is Reset, it is registered with the clock at most once.
+//
+// Reset discards any value from an earlier expiry which has not been received,
+// so the timer's channel never holds more than one value, and the value it
+// holds after Reset is from the new expiry.
 func (s *sleeper) Reset(d time.Duration) bool {
-	active := s.Stop()
-	s.until = s.fc.Now().Add(d)
-	defer s.fc.addTimer(s)
-	defer atomic.StoreUint32(&s.done, 0)
+	return s.reset(func(now time.Time) time.Time { return now.Add(d) })
+}
+
+// reset re-arms the sleeper to expire at the time returned by until, which is
+// called with the clock's current time while fc.l is held.
+func (s *sleeper) reset(until func(now time.Time) time.Time) bool {
+	fc := s.fc
+	fc.l.Lock()
+	active := fc.stopTimerLocked(s)
+	if s.drain() {
+		if fc.go123Timers {
+			// The timer fired, but as nobody received the value it counts
+			// as active, like an unbuffered channel in Go 1.23.
+			active = true
+		} else {
+			// With the real pre-1.23 timers the value would have been
+			// received after the Reset, as though from the new expiry.
+			atomic.AddUint64(&fc.staleResets, 1)
+		}
+	}
+	s.SetUntil(until(fc.time))
+	atomic.StoreUint32(&s.done, 0)
+	now, gen := fc.time, s.gen
+	due := fc.addTimerLocked(s)
+	fc.l.Unlock()
+
+	if due {
+		s.awaken(now, gen, goFunc)
+	}
 	return active
 }
 
+// Pause removes the sleeper from the clock, keeping the time it has left.
+func (s *sleeper) Pause() bool {
+	fc := s.fc
+	fc.l.Lock()
+	defer fc.l.Unlock()
+	if s.paused || !fc.stopTimerLocked(s) {
+		return false
+	}
+	s.paused = true
+	s.left = s.Until().Sub(fc.time)
+	return true
+}
+
+// Resume re-adds a paused sleeper to the clock, due once its time left has
+// passed.
+func (s *sleeper) Resume() bool {
+	fc := s.fc
+	fc.l.Lock()
+	if !s.paused {
+		fc.l.Unlock()
+		return false
+	}
+	s.paused = false
+	s.SetUntil(fc.time.Add(s.left))
+	atomic.StoreUint32(&s.done, 0)
+	now, gen := fc.time, s.gen
+	due := fc.addTimerLocked(s)
+	fc.l.Unlock()
+
+	if due {
+		s.awaken(now, gen, goFunc)
+	}
+	return true
+}
+
 func (s *sleeper) Until() time.Time {
 	s.l.RLock()
 	defer s.l.RUnlock()
@@ -169,74 +985,300 @@ func (s *sleeper) SetUntil(t time.Time) {
 	s.until = t
 }
 
+// Stop prevents the timer from firing, and reports whether it did so. For a
+// timer created by AfterFunc, the timer fires as soon as Advance or Set claims
+// it, before its function has been started: from then on Stop returns false,
+// and the function is guaranteed to run, however soon after the Advance Stop
+// is called.
 func (s *sleeper) Stop() bool {
-	stopped := atomic.CompareAndSwapUint32(&s.done, 0, 1)
+	s.fc.l.Lock()
+	defer s.fc.l.Unlock()
+	stopped := s.fc.stopTimerLocked(s)
+	if s.fc.go123Timers && s.drain() {
+		// The timer fired, but as nobody received the value it counts as
+		// stopped, like an unbuffered channel in Go 1.23.
+		stopped = true
+	}
 	if stopped {
-		s.SetUntil(s.fc.Now()) // Expire the timer
-		s.fc.Advance(0)        // Notify blockers
+		s.countTimer(&s.fc.stats.timersStopped)
 	}
 	return stopped
 }
 
+// drain discards any value buffered in the sleeper's channel, and any firing
+// whose value has not been sent yet, reporting whether there was one buffered.
+// The caller must hold fc.l.
+func (s *sleeper) drain() bool {
+	s.sendL.Lock()
+	defer s.sendL.Unlock()
+	s.gen++
+	if f := s.inflight; f != nil {
+		// Wait for the goroutine, so that once drain returns no value from
+		// before it can be received.
+		s.inflight = nil
+		close(f.cancel)
+		<-f.exited
+		return f.discarded
+	}
+	return drain(s.ch)
+}
+
+func drain(c chan time.Time) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
 // After mimics time.After; it waits for the given duration to elapse on the
 // fakeClock, then sends the current time on the returned channel.
 func (fc *fakeClock) After(d time.Duration) <-chan time.Time {
-	return fc.NewTimer(d).C()
+	return fc.newTimerAt(fc.exactNow().Add(d), "After").C()
 }
 
 // NewTimer creates a new Timer that will send the current time on its channel
 // after the given duration elapses on the fake clock.
 func (fc *fakeClock) NewTimer(d time.Duration) Timer {
-	done := make(chan time.Time, 1)
+	return fc.newTimerAt(fc.exactNow().Add(d), "NewTimer")
+}
+
+// NewTimerAt creates a new Timer that will send the current time on its
+// channel once the fake clock reaches t.
+func (fc *fakeClock) NewTimerAt(t time.Time) Timer {
+	return fc.newTimerAt(t, "NewTimerAt")
+}
+
+// AfterAt waits for the fake clock to reach t, then sends the current time on
+// the returned channel.
+func (fc *fakeClock) AfterAt(t time.Time) <-chan time.Time {
+	return fc.newTimerAt(t, "AfterAt").C()
+}
+
+func (fc *fakeClock) AfterString(s string) (<-chan time.Time, error) {
+	return afterString(fc, s)
+}
+
+func (fc *fakeClock) NewTimerString(s string) (Timer, error) {
+	return newTimerString(fc, s)
+}
+
+// afterString implements AfterString for c, in terms of its After.
+func afterString(c Clock, s string) (<-chan time.Time, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return nil, err
+	}
+	return c.After(d), nil
+}
+
+// newTimerString implements NewTimerString for c, in terms of its NewTimer.
+func newTimerString(c Clock, s string) (Timer, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return nil, err
+	}
+	return c.NewTimer(d), nil
+}
+
+// newTimerAt creates a sleeper that will send the current time on its channel
+// once the fake clock reaches until. The label describes how the sleeper was
+// created, for diagnostics.
+func (fc *fakeClock) newTimerAt(until time.Time, label string) *sleeper {
+	return fc.newSleeperAt(until, label, timerSleeper)
+}
+
+// newSleeperAt is newTimerAt for a sleeper of the given kind.
+func (fc *fakeClock) newSleeperAt(until time.Time, label string, kind sleeperKind) *sleeper {
+	buf := 1
+	if fc.unbufferedTimers {
+		buf = 0
+	}
+	done := make(chan time.Time, buf)
 	s := &sleeper{
-		fc: fc,
-		// Use fc.Now() to ensure fc.l is held when accessing fc.time.
-		until:    fc.Now().Add(d),
+		fc:       fc,
+		label:    label,
+		until:    until,
 		callback: sendTime,
 		arg:      done,
 		ch:       done,
+		kind:     kind,
+	}
+	if kind == sleepSleeper {
+		s.wake = make(chan struct{})
 	}
 	fc.addTimer(s)
 	return s
 }
 
 // AfterFunc waits for the duration to elapse on the fake clock and then calls f
-// in its own goroutine.
+// in its own goroutine. Callbacks due at the same instant are started in the
+// order they were armed, although they then run concurrently.
 // It returns a Timer that can be used to cancel the call using its Stop method.
+// Stop only cancels the call if it returns true; once it returns false, f has
+// been or will be called.
 func (fc *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
-	s := &sleeper{
-		fc: fc,
-		// Use fc.Now() to ensure fc.l is held when accessing fc.time.
-		until:    fc.Now().Add(d),
-		callback: goFunc,
-		arg:      f,
+	s := fc.newAfterFunc(d, f)
+	fc.addTimer(s)
+	return s
+}
+
+// newAfterFunc creates the sleeper for AfterFunc, without adding it.
+func (fc *fakeClock) newAfterFunc(d time.Duration, f func()) *sleeper {
+	return &sleeper{
+		fc:    fc,
+		until: fc.exactNow().Add(d),
+		fn:    f,
+		label: "AfterFunc",
 		// zero-valued ch, the same as it is in the `time` pkg
 	}
+}
+
+// AfterFuncDone is like AfterFunc, but also returns a channel which is closed
+// once f has returned, or once the Timer is stopped before f runs, including
+// by the fakeClock's Stop or Close.
+func (fc *fakeClock) AfterFuncDone(d time.Duration, f func()) (Timer, <-chan struct{}) {
+	dt := &doneTimer{done: make(chan struct{})}
+	s := fc.newAfterFunc(d, func() {
+		defer dt.close()
+		f()
+	})
+	s.discarded = dt.close
+	dt.Timer = s
 	fc.addTimer(s)
-	return s
+	return dt, dt.done
+}
+
+// DeadlineContext returns a context which is done when the fakeClock reaches
+// t, or when parent is done or the CancelFunc is called, if sooner. The
+// context is done as soon as an Advance or Set reaches t, before it returns.
+func (fc *fakeClock) DeadlineContext(parent context.Context, t time.Time) (context.Context, context.CancelFunc) {
+	tc := &timeoutCtx{
+		parent:   parent,
+		deadline: t,
+		done:     make(chan struct{}),
+	}
+	s := &sleeper{
+		fc:    fc,
+		label: "DeadlineContext",
+		until: t,
+		// Called from awaken, so Done is closed before Advance returns.
+		callback: expireTimeout,
+		arg:      tc,
+	}
+	tc.timer = s
+	fc.addTimer(s)
+	if parent.Done() != nil {
+		go func() {
+			select {
+			case <-parent.Done():
+				tc.cancel(parent.Err())
+			case <-tc.done:
+			}
+		}()
+	}
+	return tc, func() { tc.cancel(context.Canceled) }
 }
 
 func (fc *fakeClock) addTimer(s *sleeper) {
+	s.countTimer(&fc.stats.timersCreated)
 	fc.l.Lock()
-	defer fc.l.Unlock()
-	now := fc.time
-	if now.Sub(s.until) >= 0 {
+	now, gen := fc.time, s.gen
+	due := fc.addTimerLocked(s)
+	fc.l.Unlock()
+	if due {
+		s.awaken(now, gen, goFunc)
+	}
+}
+
+// addTimerLocked registers s with the clock. If s is already due it is claimed
+// instead, and addTimerLocked returns true: the caller must then awaken s
+// after releasing fc.l. The caller must hold fc.l.
+func (fc *fakeClock) addTimerLocked(s *sleeper) (due bool) {
+	fc.logEvent(EventTimerCreated, fc.time, s.label)
+	if reached(fc.time, s.Until()) {
 		// special case - trigger immediately
-		s.awaken(now)
-	} else {
-		// otherwise, add to the set of sleepers
-		fc.sleepers = append(fc.sleepers, s)
-		// and notify any blockers
-		fc.blockers = notifyBlockers(fc.blockers, len(fc.sleepers))
+		return atomic.CompareAndSwapUint32(&s.done, 0, 1)
+	}
+	// otherwise, add to the set of sleepers
+	fc.armLocked(s)
+	// and notify any blockers
+	fc.blockers = notifyBlockers(fc.blockers, len(fc.sleepers))
+	return false
+}
+
+// armLocked adds s to the pending sleepers, without notifying blockers. The
+// caller must hold fc.l.
+func (fc *fakeClock) armLocked(s *sleeper) {
+	if fc.watchdog > 0 {
+		s.armed, s.reported = time.Now(), false
 	}
+	fc.sleepers = append(fc.sleepers, s)
+}
+
+// tickLocked ticks the tickers whose sleepers are among due, now that the
+// clock has reached now, and re-arms those sleepers for the tickers' next
+// ticks. It returns the rest of due, filtered in place. The caller must hold
+// fc.l.
+func (fc *fakeClock) tickLocked(due []*sleeper, now time.Time) []*sleeper {
+	timers := due[:0]
+	for _, s := range due {
+		ft := s.ticker
+		if ft == nil {
+			timers = append(timers, s)
+			continue
+		}
+		fc.logEvent(EventTimerFired, now, s.label)
+		next, stopped := ft.tick(s.Until(), now)
+		if stopped {
+			fc.removeTickerLocked(ft)
+			continue
+		}
+		s.SetUntil(next)
+		atomic.StoreUint32(&s.done, 0)
+		fc.armLocked(s)
+	}
+	return timers
+}
+
+// stopTimerLocked stops s if it is pending, removing it from the set of
+// sleepers and notifying any blockers, and reports whether it did. The caller
+// must hold fc.l.
+//
+// Every change to a sleeper's done flag is made with fc.l held, so a sleeper
+// is in fc.sleepers exactly when it is pending.
+func (fc *fakeClock) stopTimerLocked(s *sleeper) bool {
+	if s.paused {
+		// A paused sleeper is already out of the sleepers, but still pending.
+		s.paused = false
+		return true
+	}
+	if !atomic.CompareAndSwapUint32(&s.done, 0, 1) {
+		return false
+	}
+	// Remove s eagerly, rather than waiting for the next Advance, so that
+	// creating and stopping many timers doesn't grow the set of sleepers.
+	for i, other := range fc.sleepers {
+		if other == s {
+			last := len(fc.sleepers) - 1
+			copy(fc.sleepers[i:], fc.sleepers[i+1:])
+			fc.sleepers[last] = nil // don't retain s in the backing array
+			fc.sleepers = fc.sleepers[:last]
+			break
+		}
+	}
+	fc.blockers = notifyBlockers(fc.blockers, len(fc.sleepers))
+	fc.logEvent(EventTimerStopped, fc.time, s.label)
+	return true
 }
 
 func sendTime(c interface{}, now time.Time) {
 	c.(chan time.Time) <- now
 }
 
-func goFunc(fn interface{}, _ time.Time) {
-	go fn.(func())()
+func goFunc(fn func(), _ string) {
+	go fn()
 }
 
 // notifyBlockers notifies all the blockers waiting until the
@@ -253,80 +1295,903 @@ func notifyBlockers(blockers []*blocker, count int) (newBlockers []*blocker) {
 	return
 }
 
-// notifySleepers finds and notifies all the sleepers waiting until time t.
-func notifySleepers(sleepers []*sleeper, t time.Time) []*sleeper {
-	var newSleepers []*sleeper
+// reached reports whether a sleeper with the given deadline is due at time now.
+// Deadlines are inclusive: a sleeper is due once the clock reaches its deadline
+// exactly, not only once the clock has passed it.
+func reached(now, deadline time.Time) bool {
+	return !now.Before(deadline)
+}
+
+// dueSleepers finds all the sleepers waiting until time t, and claims those
+// which are due by setting their done flag. It returns the sleepers which are
+// still waiting, and those which are due in the order they should be notified:
+// in order of their deadlines, with sleepers sharing a deadline in the order
+// they were added to the clock.
+//
+// The sleepers still waiting are filtered in place, reusing the backing array
+// of sleepers.
+func dueSleepers(sleepers []*sleeper, t time.Time) (newSleepers, due []*sleeper) {
+	newSleepers = sleepers[:0]
 	for _, s := range sleepers {
-		if t.Sub(s.Until()) >= 0 {
-			s.awaken(t)
+		if reached(t, s.Until()) {
+			atomic.StoreUint32(&s.done, 1)
+			due = append(due, s)
 		} else {
 			newSleepers = append(newSleepers, s)
 		}
 	}
-	return newSleepers
+	// Don't retain the due sleepers in the unused tail of the backing array.
+	for i := len(newSleepers); i < len(sleepers); i++ {
+		sleepers[i] = nil
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].Until().Before(due[j].Until())
+	})
+	return newSleepers, due
 }
 
-// Sleep blocks until the given duration has passed on the fakeClock
+// Sleep blocks until the given duration has passed on the fakeClock, or until
+// the fakeClock is stopped.
 func (fc *fakeClock) Sleep(d time.Duration) {
-	<-fc.After(d)
+	fc.sleep(fc.exactNow().Add(d), "Sleep")
+}
+
+// SleepUntil blocks until the fakeClock reaches t, or until it is stopped.
+func (fc *fakeClock) SleepUntil(t time.Time) {
+	fc.sleep(t, "SleepUntil")
+}
+
+func (fc *fakeClock) sleep(until time.Time, label string) {
+	t := fc.newSleeperAt(until, label, sleepSleeper)
+	fc.addSleeping(1)
+	defer fc.addSleeping(-1)
+	select {
+	case <-t.C():
+	case <-t.wake:
+	case <-fc.stopped():
+		t.Stop()
+	}
+}
+
+// addSleeping adjusts the number of goroutines blocked in Sleep or SleepUntil
+// by delta.
+func (fc *fakeClock) addSleeping(delta int) {
+	fc.l.Lock()
+	defer fc.l.Unlock()
+	fc.sleeping += delta
+	fc.sleepBlockers = notifyBlockers(fc.sleepBlockers, fc.sleeping)
 }
 
-// Time returns the current time of the fakeClock
+// stopped returns a channel which is closed once the fakeClock is stopped.
+func (fc *fakeClock) stopped() <-chan struct{} {
+	fc.l.Lock()
+	defer fc.l.Unlock()
+	return fc.doneChan()
+}
+
+// doneChan returns fc.done, creating it if needed. The caller must hold fc.l.
+func (fc *fakeClock) doneChan() chan struct{} {
+	if fc.done == nil {
+		fc.done = make(chan struct{})
+	}
+	return fc.done
+}
+
+// Now returns the current time of the fakeClock, truncated to the resolution
+// set by WithResolution. It does not take fc.l: the time is published
+// atomically whenever it changes.
 func (fc *fakeClock) Now() time.Time {
-	fc.l.RLock()
-	defer fc.l.RUnlock()
-	return fc.time
+	t := fc.exactNow()
+	if fc.resolution > 0 {
+		t = t.Truncate(fc.resolution)
+	}
+	return t
+}
+
+// exactNow returns the current time of the fakeClock at full precision, which
+// is used for scheduling whatever the resolution of Now.
+func (fc *fakeClock) exactNow() time.Time {
+	t, _ := fc.now.Load().(time.Time) // nil, giving the zero time, for a zero fakeClock
+	return t
+}
+
+// setTime sets the fakeClock's time and publishes it for Now. The caller must
+// hold fc.l, unless fc is not yet shared.
+func (fc *fakeClock) setTime(t time.Time) {
+	// Strip any monotonic reading, such as one from time.Now passed to
+	// NewFakeClockNow, so that the fakeClock's times only ever compare by
+	// their wall clock readings.
+	t = t.Round(0)
+	fc.time = t
+	fc.now.Store(t)
 }
 
-// Since returns the duration that has passed since the given time on the fakeClock
+// NowUnixNano returns the fakeClock's current time in Unix nanoseconds. Like
+// Now, it doesn't take fc.l.
+func (fc *fakeClock) NowUnixNano() int64 {
+	return fc.Now().UnixNano()
+}
+
+// NowUnixMilli returns the fakeClock's current time in Unix milliseconds.
+func (fc *fakeClock) NowUnixMilli() int64 {
+	return unixMilli(fc.Now())
+}
+
+// NowUnix returns the fakeClock's current time in Unix seconds.
+func (fc *fakeClock) NowUnix() int64 {
+	return fc.Now().Unix()
+}
+
+// Since returns the duration that has passed since the given time on the fakeClock.
+// Like time.Time.Sub it compares instants, so the locations of t and of the
+// clock's current time don't matter.
 func (fc *fakeClock) Since(t time.Time) time.Duration {
 	return fc.Now().Sub(t)
 }
 
+// Until returns the duration until the given time on the fakeClock.
+func (fc *fakeClock) Until(t time.Time) time.Duration {
+	return t.Sub(fc.Now())
+}
+
+// NextMidnight returns the next midnight after the fakeClock's current time, in
+// the location of the current time.
+func (fc *fakeClock) NextMidnight() time.Time {
+	return nextMidnight(fc.Now())
+}
+
+// NowTruncated returns the fakeClock's current time truncated to a multiple
+// of d.
+func (fc *fakeClock) NowTruncated(d time.Duration) time.Time {
+	return fc.Now().Truncate(d)
+}
+
+// NowRounded returns the fakeClock's current time rounded to the nearest
+// multiple of d.
+func (fc *fakeClock) NowRounded(d time.Duration) time.Time {
+	return fc.Now().Round(d)
+}
+
+// Date returns the time.Date with the given fields in the location of the
+// fakeClock's current time, which is UTC unless the clock was set otherwise.
+func (fc *fakeClock) Date(year int, month time.Month, day, hour, min, sec, nsec int) time.Time {
+	return time.Date(year, month, day, hour, min, sec, nsec, fc.Now().Location())
+}
+
+// unixMilli returns t as Unix milliseconds, like time.Time.UnixMilli, which
+// is not available before Go 1.17.
+func unixMilli(t time.Time) int64 {
+	return t.Unix()*1e3 + int64(t.Nanosecond())/1e6
+}
+
+// nextMidnight returns the first 00:00 strictly after t in t's location.
+func nextMidnight(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+}
+
 func (fc *fakeClock) NewTicker(d time.Duration) Ticker {
+	return fc.newTicker(d, 0, 1)
+}
+
+// NewTickerImmediate returns a Ticker that ticks at the fakeClock's current
+// time, and then every d thereafter.
+func (fc *fakeClock) NewTickerImmediate(d time.Duration) Ticker {
+	if d <= 0 {
+		panic(ErrNonPositiveInterval)
+	}
+	// The first tick is buffered before the ticker is registered, so that a
+	// concurrent Advance can't fill the channel first.
+	now := fc.exactNow()
+	ft := &fakeTicker{period: d, c: make(chan time.Time, 1)}
+	ft.c <- now
+	return fc.runTicker(ft, now.Add(d), 1)
+}
+
+func (fc *fakeClock) TryNewTicker(d time.Duration) (Ticker, error) {
 	if d <= 0 {
-		panic(errors.New("non-positive interval for NewTicker"))
+		return nil, ErrNonPositiveInterval
+	}
+	return fc.newTicker(d, 0, 1), nil
+}
+
+// NewBurstTicker returns a Ticker which stops itself once it has delivered n
+// ticks. Ticks discarded because the previous one was unread don't count.
+func (fc *fakeClock) NewBurstTicker(d time.Duration, n int) Ticker {
+	if n <= 0 {
+		panic(ErrNonPositiveBurst)
 	}
-	ft := &fakeTicker{
-		c:      make(chan time.Time, 1),
-		stop:   make(chan bool, 1),
-		clock:  fc,
-		period: d,
+	return fc.newTicker(d, n, 1)
+}
+
+// NewBufferedTicker returns a Ticker whose channel holds up to buf ticks. When
+// an Advance or Set skips several periods, a tick is delivered for each of
+// them until the channel is full, rather than only for the last.
+func (fc *fakeClock) NewBufferedTicker(d time.Duration, buf int) Ticker {
+	if buf <= 0 {
+		panic(ErrNonPositiveBuffer)
 	}
-	ft.runTickThread()
+	return fc.newTicker(d, 0, buf)
+}
+
+// NewAlignedTicker returns a Ticker which ticks whenever the fakeClock's time
+// reaches a multiple of d, starting with the first after its current time.
+func (fc *fakeClock) NewAlignedTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic(ErrNonPositiveInterval)
+	}
+	return fc.startTicker(fc.exactNow().Truncate(d).Add(d), d, 0, 1)
+}
+
+// NewSkewedTicker returns a ticker whose nth tick is due n periods of d, plus
+// skew(n), after it is created. Ticks carry the time they were due.
+func (fc *fakeClock) NewSkewedTicker(d time.Duration, skew func(n int) time.Duration) Ticker {
+	if d <= 0 {
+		panic(ErrNonPositiveInterval)
+	}
+	if skew == nil {
+		return fc.NewTicker(d)
+	}
+	ft := &fakeTicker{period: d, skew: skew, start: fc.exactNow()}
+	return fc.runTicker(ft, ft.nextSkewed(ft.start), 1)
+}
+
+// newTicker starts a ticker with period d and a channel holding buf ticks,
+// which stops itself after delivering n ticks if n is positive.
+func (fc *fakeClock) newTicker(d time.Duration, n, buf int) *fakeTicker {
+	if d <= 0 {
+		panic(ErrNonPositiveInterval)
+	}
+	return fc.startTicker(fc.exactNow().Add(d), d, n, buf)
+}
+
+// startTicker starts a ticker as for newTicker, whose first tick is at first.
+func (fc *fakeClock) startTicker(first time.Time, d time.Duration, n, buf int) *fakeTicker {
+	return fc.runTicker(&fakeTicker{period: d, remaining: n}, first, buf)
+}
+
+// runTicker registers ft, whose first tick is due at first, with a channel
+// holding up to buf ticks, unless ft already has a channel.
+func (fc *fakeClock) runTicker(ft *fakeTicker, first time.Time, buf int) *fakeTicker {
+	count(&fc.stats.tickersCreated)
+	if ft.c == nil {
+		ft.c = make(chan time.Time, buf)
+	}
+	ft.clock = fc
+	ft.next = &sleeper{
+		fc:     fc,
+		label:  "Ticker",
+		kind:   tickerSleeper,
+		until:  first,
+		ticker: ft,
+	}
+	fc.l.Lock()
+	defer fc.l.Unlock()
+	fc.tickers = append(fc.tickers, ft)
+	// The first tick is always in the future, so the sleeper is never due yet.
+	fc.addTimerLocked(ft.next)
 	return ft
 }
 
-// set sets the fakeClock and notifies sleepers and blockers before returning.
-// The caller must hold fc.l for the duration.
-func (fc *fakeClock) set(t time.Time) {
-	fc.sleepers = notifySleepers(fc.sleepers, t)
+// removeTickerLocked deregisters ft, once it has stopped. The caller must hold
+// fc.l.
+func (fc *fakeClock) removeTickerLocked(ft *fakeTicker) {
+	for i, other := range fc.tickers {
+		if other == ft {
+			fc.tickers = append(fc.tickers[:i:i], fc.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+// NewStepTicker returns a Ticker which ticks once per Advance or Set. A tick
+// which finds the previous one unread is discarded, as for other tickers.
+func (fc *fakeClock) NewStepTicker() Ticker {
+	st := &stepTicker{
+		c:     make(chan time.Time, 1),
+		clock: fc,
+	}
+	fc.l.Lock()
+	defer fc.l.Unlock()
+	fc.stepTickers = append(fc.stepTickers, st)
+	return st
+}
+
+// set sets the fakeClock to the time returned by to, which is called with the
+// current time, and notifies sleepers and blockers before returning. Functions
+// scheduled with AfterFunc which become due are passed to run.
+//
+// Blockers are notified while fc.l is held, but sleepers are notified after it
+// has been released, so that their callbacks may safely use the clock (for
+// example to Stop another timer).
+func (fc *fakeClock) set(typ EventType, to func(now time.Time) time.Time, run func(fn func(), label string)) {
+	fc.l.Lock()
+	t := to(fc.time)
+	if fc.maxAdvance > 0 {
+		move := t.Sub(fc.time)
+		if move < 0 {
+			move = -move
+		}
+		if move > fc.maxAdvance {
+			fc.l.Unlock()
+			panic(&wrappedError{ErrAdvanceLimit, fmt.Sprintf("moving the clock by %v exceeds the maximum of %v", t.Sub(fc.time), fc.maxAdvance)})
+		}
+	}
+	if typ == EventAdvance {
+		count(&fc.stats.advanceCalls)
+	}
+	from := fc.time
+	var due []*sleeper
+	fc.sleepers, due = dueSleepers(fc.sleepers, t)
+	if fc.maxFires > 0 && len(due) > fc.maxFires {
+		// The rest stay pending, ahead of the other sleepers, for the next
+		// move to fire.
+		deferred := due[fc.maxFires:len(due):len(due)]
+		for _, s := range deferred {
+			atomic.StoreUint32(&s.done, 0)
+		}
+		fc.sleepers = append(deferred, fc.sleepers...)
+		due = due[:fc.maxFires]
+	}
+	if t.After(fc.time) {
+		fc.monotonic += t.Sub(fc.time)
+	}
+	fc.setTime(t)
+	fc.logEvent(typ, t, "")
+	// Ticks are delivered while fc.l is held, so that a ticker is re-armed
+	// before anyone can see it missing from the sleepers.
+	due = fc.tickLocked(due, t)
+	gens := make([]uint64, len(due))
+	for i, s := range due {
+		gens[i] = s.gen
+	}
 	fc.blockers = notifyBlockers(fc.blockers, len(fc.sleepers))
-	fc.time = t
+	stepTickers := append([]*stepTicker(nil), fc.stepTickers...)
+	onNextMove := fc.onNextMove
+	fc.onNextMove = nil
+	fc.l.Unlock()
+
+	for i, s := range due {
+		s.awaken(t, gens[i], run)
+	}
+	for _, st := range stepTickers {
+		st.send(t)
+	}
+	for _, f := range onNextMove {
+		f(from, t)
+	}
 }
 
 // Advance advances fakeClock to a new point in time, ensuring channels from any
 // previous invocations of After are notified appropriately before returning
+//
+// Advance is safe to call from multiple goroutines. Concurrent calls are
+// serialized: each one moves the clock from wherever the previous one left it,
+// so the final time is the sum of all advances whatever order they run in, and
+// every sleeper is notified exactly once. The order in which the calls take
+// effect is unspecified, so concurrent BlockUntil callers may observe any
+// interleaving of their effects.
 func (fc *fakeClock) Advance(d time.Duration) {
-	fc.l.Lock()
-	defer fc.l.Unlock()
-	fc.set(fc.time.Add(d))
+	fc.set(EventAdvance, func(now time.Time) time.Time { return now.Add(d) }, goFunc)
+}
+
+// AdvanceAndWait advances the fakeClock like Advance, and then waits for the
+// functions scheduled with AfterFunc which became due to return.
+//
+// A function which waits for something that only happens after AdvanceAndWait
+// returns, such as a further call to Advance from the test, deadlocks it.
+func (fc *fakeClock) AdvanceAndWait(d time.Duration) {
+	var wg sync.WaitGroup
+	fc.set(EventAdvance, func(now time.Time) time.Time { return now.Add(d) }, func(f func(), _ string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f()
+		}()
+	})
+	wg.Wait()
+}
+
+// AdvanceSafe advances the fakeClock like Advance, running the functions which
+// became due itself and recovering their panics.
+func (fc *fakeClock) AdvanceSafe(d time.Duration) error {
+	var err error
+	fc.set(EventAdvance, func(now time.Time) time.Time { return now.Add(d) }, func(f func(), label string) {
+		if perr := runRecovered(f, label); perr != nil && err == nil {
+			err = perr
+		}
+	})
+	return err
+}
+
+// runRecovered calls f, returning a *CallbackPanicError if it panics.
+func runRecovered(f func(), label string) (err *CallbackPanicError) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &CallbackPanicError{Label: label, Value: v, Stack: debug.Stack()}
+		}
+	}()
+	f()
+	return nil
+}
+
+// CallbackPanicError is returned by AdvanceSafe when a function scheduled with
+// AfterFunc panics.
+type CallbackPanicError struct {
+	Label string      // the label of the timer whose function panicked
+	Value interface{} // the value passed to panic
+	Stack []byte      // the stack of the panicking goroutine, from debug.Stack
+}
+
+func (e *CallbackPanicError) Error() string {
+	return fmt.Sprintf("%s function panicked: %v", e.Label, e.Value)
+}
+
+// Unwrap returns the value passed to panic, if it is an error.
+func (e *CallbackPanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// defaultYields is the number of times AdvanceAndYield yields the processor,
+// unless set by WithYields.
+const defaultYields = 10
+
+// AdvanceAndYield advances the fakeClock like Advance, and then calls
+// runtime.Gosched a number of times, set by WithYields, to let the goroutines
+// running functions scheduled with AfterFunc make progress. It is a lighter
+// alternative to AdvanceAndWait for functions which run quickly without
+// blocking, and can't deadlock however the functions behave, but it gives no
+// guarantee that they have run, or finished running, when it returns.
+func (fc *fakeClock) AdvanceAndYield(d time.Duration) {
+	fc.Advance(d)
+	yields := fc.yields
+	if yields == 0 {
+		yields = defaultYields
+	}
+	for i := 0; i < yields; i++ {
+		runtime.Gosched()
+	}
+}
+
+// AdvanceUntil advances the fakeClock by step at a time, notifying sleepers
+// after each step, until pred returns true for the current time. pred is
+// checked before the first step, so AdvanceUntil returns immediately if it is
+// already satisfied. It returns an error, leaving the clock where it stopped,
+// if pred is still false after maxSteps steps.
+func (fc *fakeClock) AdvanceUntil(pred func(time.Time) bool, step time.Duration, maxSteps int) error {
+	for i := 0; !pred(fc.Now()); i++ {
+		if i == maxSteps {
+			return fmt.Errorf("condition not reached after %d steps of %v", maxSteps, step)
+		}
+		fc.Advance(step)
+	}
+	return nil
+}
+
+// AdvanceSteps moves the fakeClock to the earliest deadline among its pending
+// sleepers, notifying every sleeper due then, and repeats n times. It is meant
+// for simulations which care about the order of events rather than the time
+// between them. Sleepers sharing a deadline fire together, in the order they
+// were armed, as a single step. Each step sees the sleepers pending when it is
+// taken, including those registered by earlier steps, such as a ticker's next
+// tick. It stops early, without moving the clock or counting an Advance, once
+// no sleepers are pending.
+func (fc *fakeClock) AdvanceSteps(n int) int {
+	for i := 0; i < n; i++ {
+		fc.l.RLock()
+		_, ok := fc.nextDeadlineLocked()
+		fc.l.RUnlock()
+		if !ok {
+			return i
+		}
+		fc.set(EventAdvance, func(now time.Time) time.Time {
+			var next time.Time
+			next, ok = fc.nextDeadlineLocked()
+			if !ok {
+				return now
+			}
+			return next
+		}, goFunc)
+		if !ok {
+			return i
+		}
+	}
+	return n
+}
+
+// AdvanceSequence advances the fakeClock by each of steps in turn, calling
+// onStep, if it is not nil, after each one.
+func (fc *fakeClock) AdvanceSequence(onStep func(i int, now time.Time), steps ...time.Duration) {
+	for i, d := range steps {
+		fc.Advance(d)
+		if onStep != nil {
+			onStep(i, fc.Now())
+		}
+	}
+}
+
+// nextDeadlineLocked returns the earliest deadline among the pending sleepers.
+// The caller must hold fc.l.
+func (fc *fakeClock) nextDeadlineLocked() (next time.Time, ok bool) {
+	for _, s := range fc.sleepers {
+		if until := s.Until(); !ok || until.Before(next) {
+			next, ok = until, true
+		}
+	}
+	return next, ok
 }
 
 // Set sets the FakeClock to a new point in time, ensuring channels from any
-// previous invocations of After are notified appropriately before returning
+// previous invocations of After are notified appropriately before returning.
+// Sleepers whose deadline is at or before t are notified, so Set(fc.Now())
+// notifies any sleeper due at exactly the current instant.
 func (fc *fakeClock) Set(t time.Time) {
+	fc.set(EventSet, func(time.Time) time.Time { return t }, goFunc)
+}
+
+// TimeRange returns the earliest and latest deadlines of the pending sleepers.
+func (fc *fakeClock) TimeRange() (earliest, latest time.Time, ok bool) {
+	fc.l.RLock()
+	defer fc.l.RUnlock()
+	for _, s := range fc.sleepers {
+		until := s.Until()
+		if !ok || until.Before(earliest) {
+			earliest = until
+		}
+		if !ok || until.After(latest) {
+			latest = until
+		}
+		ok = true
+	}
+	return earliest, latest, ok
+}
+
+// OnFire adds an observer to the sleeper behind t.
+func (fc *fakeClock) OnFire(t Timer, f func(time.Time)) {
+	s, ok := t.(*sleeper)
+	if !ok || s.fc != fc {
+		panic(errors.New("OnFire called with a Timer from a different clock"))
+	}
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.observers = append(s.observers[:len(s.observers):len(s.observers)], f)
+}
+
+// OnNextAdvance adds f to the functions called by the next set.
+func (fc *fakeClock) OnNextAdvance(f func(from, to time.Time)) {
+	fc.l.Lock()
+	defer fc.l.Unlock()
+	fc.onNextMove = append(fc.onNextMove, f)
+}
+
+// WaitTick advances the fakeClock to the next tick of tk and receives it.
+func (fc *fakeClock) WaitTick(tk Ticker) time.Time {
+	ft := fc.runningTicker(tk, "WaitTick")
+	for drain(ft.c) {
+	}
+	fc.advanceToNextTick(ft)
+	// The tick is sent during Advance, if at all.
+	select {
+	case tick := <-ft.c:
+		return tick
+	default:
+		panic(errors.New("WaitTick advanced to the next tick, but no tick was delivered"))
+	}
+}
+
+// AdvanceToNextTick advances the fakeClock to the next tick of tk.
+func (fc *fakeClock) AdvanceToNextTick(tk Ticker) {
+	fc.advanceToNextTick(fc.runningTicker(tk, "AdvanceToNextTick"))
+}
+
+func (fc *fakeClock) advanceToNextTick(ft *fakeTicker) {
+	fc.Advance(ft.next.Until().Sub(fc.exactNow()))
+}
+
+// runningTicker returns tk as a fakeTicker, panicking with a message naming
+// method unless it is a running ticker created by fc.
+func (fc *fakeClock) runningTicker(tk Ticker, method string) *fakeTicker {
+	ft, ok := tk.(*fakeTicker)
+	if !ok {
+		panic(fmt.Errorf("%s called with an unsupported Ticker type %T", method, tk))
+	}
+	if ft.clock != fc {
+		panic(fmt.Errorf("%s called with a Ticker from a different clock", method))
+	}
+	ft.l.Lock()
+	stopped := ft.stopped
+	ft.l.Unlock()
+	if stopped {
+		panic(fmt.Errorf("%s called with a stopped Ticker", method))
+	}
+	return ft
+}
+
+// Jump moves the fakeClock's time, and the deadlines of its pending sleepers,
+// including those of its skewed tickers and DeadlineContext contexts, by d.
+func (fc *fakeClock) Jump(d time.Duration) {
 	fc.l.Lock()
 	defer fc.l.Unlock()
-	fc.set(t)
+	fc.jumpLocked(d)
+}
+
+// jumpLocked implements Jump. The caller must hold fc.l.
+func (fc *fakeClock) jumpLocked(d time.Duration) {
+	for _, s := range fc.sleepers {
+		s.SetUntil(s.Until().Add(d))
+		// A skewed ticker computes its ticks from start, and a context
+		// reports its deadline, so both move with the sleeper.
+		if ft := s.ticker; ft != nil && ft.skew != nil {
+			ft.start = ft.start.Add(d)
+		}
+		if tc, ok := s.arg.(*timeoutCtx); ok {
+			tc.shift(d)
+		}
+	}
+	t := fc.time.Add(d)
+	fc.setTime(t)
+	fc.logEvent(EventJump, t, "")
+}
+
+// ErrClockRewind is wrapped by the value AdvanceMonotonic panics with when
+// asked to move the monotonic clock backward.
+var ErrClockRewind = errors.New("monotonic clock moved backward")
+
+// AdvanceMonotonic advances the fakeClock like Advance, but panics with an
+// error wrapping ErrClockRewind if d is negative, as the monotonic clock can't
+// go backward.
+func (fc *fakeClock) AdvanceMonotonic(d time.Duration) {
+	if d < 0 {
+		panic(&wrappedError{ErrClockRewind, fmt.Sprintf("negative monotonic advance %v", d)})
+	}
+	fc.Advance(d)
+}
+
+// wrappedError adds detail to a sentinel error. It unwraps to the sentinel
+// for errors.Is, without needing fmt's %w, which Go releases before 1.13
+// lack.
+type wrappedError struct {
+	err    error
+	detail string
+}
+
+func (e *wrappedError) Error() string { return e.err.Error() + ": " + e.detail }
+
+// Unwrap returns the sentinel error.
+func (e *wrappedError) Unwrap() error { return e.err }
+
+// SetWall jumps the fakeClock's wall time to t without moving its monotonic
+// time. See Jump.
+func (fc *fakeClock) SetWall(t time.Time) {
+	fc.l.Lock()
+	defer fc.l.Unlock()
+	fc.jumpLocked(t.Sub(fc.time))
+}
+
+// Monotonic returns the monotonic time elapsed since the fakeClock was
+// created.
+func (fc *fakeClock) Monotonic() time.Duration {
+	fc.l.RLock()
+	defer fc.l.RUnlock()
+	return fc.monotonic
+}
+
+// Clone returns a new FakeClock at the fakeClock's exact current time, which
+// shares nothing else with it.
+func (fc *fakeClock) Clone(opts ...Option) FakeClock {
+	return NewFakeClockAt(fc.exactNow(), opts...)
+}
+
+// ActiveTickers returns the number of running tickers. A fakeTicker is
+// registered when it is created, and deregistered when it is stopped, by Stop
+// or by delivering its last tick.
+func (fc *fakeClock) ActiveTickers() int {
+	fc.l.RLock()
+	defer fc.l.RUnlock()
+	return len(fc.tickers) + len(fc.stepTickers)
+}
+
+// TickerOverruns returns the number of ticks discarded because the consumer
+// had not read the previous tick, if the clock was created WithStrictTickers.
+func (fc *fakeClock) TickerOverruns() int {
+	return int(atomic.LoadUint64(&fc.tickerOverruns))
+}
+
+// StaleResets returns the number of timers Reset with a value still unread.
+func (fc *fakeClock) StaleResets() int {
+	return int(atomic.LoadUint64(&fc.staleResets))
+}
+
+// tickerOverrun records that a ticker discarded a tick.
+func (fc *fakeClock) tickerOverrun() {
+	if fc.strictTickers {
+		atomic.AddUint64(&fc.tickerOverruns, 1)
+	}
+}
+
+// queueCallback queues a due AfterFunc function for RunPendingCallbacks.
+func (fc *fakeClock) queueCallback(f func()) {
+	fc.callbacksL.Lock()
+	defer fc.callbacksL.Unlock()
+	fc.callbacks = append(fc.callbacks, f)
+}
+
+// RunPendingCallbacks runs the AfterFunc functions queued so far. Functions
+// which become due while it is running, for example because a function
+// schedules another with a zero duration, are left for the next call.
+func (fc *fakeClock) RunPendingCallbacks() int {
+	fc.callbacksL.Lock()
+	callbacks := fc.callbacks
+	fc.callbacks = nil
+	fc.callbacksL.Unlock()
+	for _, f := range callbacks {
+		f()
+	}
+	return len(callbacks)
+}
+
+// Scope runs f, then restores the fakeClock's time to what it was before f was
+// called and stops any sleepers created during f which are still pending. As
+// with Close, tickers created during f, including step tickers, are stopped,
+// and goroutines blocked in Sleep on a sleeper created during f are woken.
+//
+// Effects on sleepers which existed before the scope cannot be undone: if they
+// fired during f they stay fired, and if they were Reset their new deadline is
+// kept. Sleepers are notified as the clock moves during f as usual, but not
+// when the time is restored.
+func (fc *fakeClock) Scope(f func()) {
+	fc.l.Lock()
+	saved := fc.time
+	before := make(map[*sleeper]bool, len(fc.sleepers))
+	for _, s := range fc.sleepers {
+		before[s] = true
+	}
+	stepsBefore := make(map[*stepTicker]bool, len(fc.stepTickers))
+	for _, st := range fc.stepTickers {
+		stepsBefore[st] = true
+	}
+	fc.l.Unlock()
+
+	defer func() {
+		fc.l.Lock()
+		var tickers []*fakeTicker
+		for _, s := range append([]*sleeper(nil), fc.sleepers...) {
+			if before[s] || !fc.stopTimerLocked(s) {
+				continue
+			}
+			if s.ticker != nil {
+				tickers = append(tickers, s.ticker)
+				fc.removeTickerLocked(s.ticker)
+			}
+			s.wakeLocked()
+		}
+		var steps []*stepTicker
+		for _, st := range fc.stepTickers {
+			if !stepsBefore[st] {
+				steps = append(steps, st)
+			}
+		}
+		fc.setTime(saved)
+		fc.l.Unlock()
+		// Stopping a ticker takes fc.l.
+		for _, ft := range tickers {
+			ft.Stop()
+		}
+		for _, st := range steps {
+			st.Stop()
+		}
+	}()
+	f()
+}
+
+// wakeLocked releases whatever waits on s, which would never return once s
+// is stopped by the clock: the goroutine blocked in Sleep on it, as if its
+// sleep had ended, or the receivers of AfterFuncDone's done channel. The
+// caller must hold fc.l.
+func (s *sleeper) wakeLocked() {
+	if s.discarded != nil {
+		s.discarded()
+	}
+	if s.kind != sleepSleeper {
+		return
+	}
+	select {
+	case <-s.wake:
+	default:
+		close(s.wake)
+	}
+}
+
+// Close stops the fakeClock's tickers, then its pending sleepers, returning
+// the number of each it stopped. It ends watching by the watchdog, which is
+// not restarted for sleepers created afterwards.
+func (fc *fakeClock) Close() (tickers, sleepers int) {
+	fc.stopWatchdog()
+	fc.l.Lock()
+	running := append([]*fakeTicker(nil), fc.tickers...)
+	steps := fc.stepTickers
+	fc.stepTickers = nil
+	fc.l.Unlock()
+	// Stopping a ticker takes fc.l.
+	for _, ft := range running {
+		ft.Stop()
+		if fc.tickerLeaks != nil {
+			fc.tickerLeaks(ft.period)
+		}
+	}
+	for _, st := range steps {
+		st.Stop()
+		if fc.tickerLeaks != nil {
+			fc.tickerLeaks(0)
+		}
+	}
+
+	fc.l.Lock()
+	for _, s := range append([]*sleeper(nil), fc.sleepers...) {
+		if fc.stopTimerLocked(s) {
+			s.countTimer(&fc.stats.timersStopped)
+			sleepers++
+			s.wakeLocked()
+		}
+	}
+	fc.l.Unlock()
+	return len(running) + len(steps), sleepers
+}
+
+// Stop discards all pending sleepers, so that they never fire, and releases
+// any goroutines blocked in Sleep, closing the done channels of functions
+// from AfterFuncDone it cancels. It also stops every ticker, including step
+// tickers. It is safe to call Stop more than once.
+func (fc *fakeClock) Stop() {
+	fc.l.Lock()
+	defer fc.l.Unlock()
+	done := fc.doneChan()
+	select {
+	case <-done:
+	default:
+		close(done)
+	}
+	for _, s := range fc.sleepers {
+		if atomic.CompareAndSwapUint32(&s.done, 0, 1) {
+			fc.logEvent(EventTimerStopped, fc.time, s.label)
+			s.wakeLocked()
+		}
+	}
+	fc.sleepers = nil
+	// Tickers are marked stopped too, so that they behave as if Stop had been
+	// called on each. fc.l is taken before ft.l, as when ticking.
+	for _, ft := range fc.tickers {
+		ft.l.Lock()
+		ft.stopped = true
+		ft.l.Unlock()
+	}
+	fc.tickers = nil
+	for _, st := range fc.stepTickers {
+		st.l.Lock()
+		st.stopped = true
+		st.l.Unlock()
+	}
+	fc.stepTickers = nil
+	fc.blockers = notifyBlockers(fc.blockers, 0)
+	fc.stopWatchdog()
 }
 
 // BlockUntil will block until the fakeClock has the given number of sleepers
 // (callers of Sleep or After)
+//
+// Every change to fc.sleepers, whether adding, stopping or firing a sleeper,
+// is made with fc.l held and followed by notifyBlockers with the new count.
+// Since the count is checked and the blocker registered under the same lock,
+// a blocker can't miss a change, including one to zero made by a concurrent
+// Advance. It waits for an exact count, though, so it is only released if the
+// count is n at the moment of some change: an Advance which fires several
+// sleepers at once moves straight past the intermediate counts.
 func (fc *fakeClock) BlockUntil(n int) {
 	fc.l.Lock()
 	// Fast path: current number of sleepers is what we're looking for
 	if len(fc.sleepers) == n {
+		fc.lastBlock = 0
 		fc.l.Unlock()
 		return
 	}
@@ -337,5 +2202,48 @@ func (fc *fakeClock) BlockUntil(n int) {
 	}
 	fc.blockers = append(fc.blockers, b)
 	fc.l.Unlock()
+	start := time.Now()
+	<-b.ch
+	blocked := time.Since(start)
+
+	fc.l.Lock()
+	fc.lastBlock = blocked
+	fc.l.Unlock()
+}
+
+// Blockers returns the counts the fakeClock's BlockUntil callers are waiting
+// for.
+func (fc *fakeClock) Blockers() []int {
+	fc.l.RLock()
+	defer fc.l.RUnlock()
+	counts := make([]int, len(fc.blockers))
+	for i, b := range fc.blockers {
+		counts[i] = b.count
+	}
+	return counts
+}
+
+// LastBlockDuration returns the real time the last call to BlockUntil to
+// return spent blocked.
+func (fc *fakeClock) LastBlockDuration() time.Duration {
+	fc.l.RLock()
+	defer fc.l.RUnlock()
+	return fc.lastBlock
+}
+
+// BlockUntilBlocked blocks until exactly n goroutines are blocked in Sleep or
+// SleepUntil.
+func (fc *fakeClock) BlockUntilBlocked(n int) {
+	fc.l.Lock()
+	if fc.sleeping == n {
+		fc.l.Unlock()
+		return
+	}
+	b := &blocker{
+		count: n,
+		ch:    make(chan struct{}),
+	}
+	fc.sleepBlockers = append(fc.sleepBlockers, b)
+	fc.l.Unlock()
 	<-b.ch
 }
@@ -1,7 +1,15 @@
 package clockwork
 
 import (
+	"context"
+	"errors"
+	"math/rand"
 	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -139,6 +147,25 @@ func TestNewFakeClockAt(t *testing.T) {
 	}
 }
 
+func TestNewFakeClockAtEpoch(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClockAtEpoch()
+	if now, want := fc.Now(), time.Unix(0, 0).UTC(); now != want {
+		t.Fatalf("fakeClock.Now() returned unexpected value: want=%#v, got %#v", want, now)
+	}
+}
+
+func TestNewFakeClockNow(t *testing.T) {
+	t.Parallel()
+	before := time.Now()
+	fc := NewFakeClockNow()
+	after := time.Now()
+	now := fc.Now()
+	if now.Before(before) || now.After(after) {
+		t.Fatalf("fakeClock.Now() = %v, want between %v and %v", now, before, after)
+	}
+}
+
 func TestFakeClockSince(t *testing.T) {
 	t.Parallel()
 	fc := NewFakeClock()
@@ -376,3 +403,1947 @@ func TestSet(t *testing.T) {
 		})
 	}
 }
+
+func TestFakeClockStopReleasesSleepers(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+
+	returned := make(chan struct{})
+	go func() {
+		fc.Sleep(time.Hour)
+		close(returned)
+	}()
+	fc.BlockUntil(1)
+
+	fc.Stop()
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatalf("Sleep did not return after Stop")
+	}
+	fc.BlockUntil(0)
+
+	// Sleeping on a stopped clock returns immediately.
+	withTimeout(t, 100*time.Millisecond, func() {
+		fc.Sleep(time.Hour)
+	})
+
+	// Stop is idempotent.
+	fc.Stop()
+}
+
+func TestFakeClockStopStopsTickers(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	ft := fc.NewTicker(time.Second).(*fakeTicker)
+
+	fc.Stop()
+	ft.l.Lock()
+	stopped := ft.stopped
+	ft.l.Unlock()
+	if !stopped {
+		t.Errorf("ticker not marked stopped after Stop")
+	}
+	if n := len(fc.(*fakeClock).tickers); n != 0 {
+		t.Errorf("%d tickers still registered after Stop", n)
+	}
+}
+
+func TestFakeClockStopStopsStepTickers(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	st := fc.NewStepTicker()
+
+	fc.Stop()
+	if n := fc.ActiveTickers(); n != 0 {
+		t.Errorf("ActiveTickers() = %d after Stop, want 0", n)
+	}
+	fc.Advance(time.Second)
+	select {
+	case tick := <-st.Chan():
+		t.Errorf("step ticker ticked at %v after Stop", tick)
+	default:
+	}
+}
+
+func TestFakeClockStopClosesAfterFuncDone(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	_, done := fc.AfterFuncDone(time.Second, func() {
+		t.Errorf("function ran after Stop")
+	})
+
+	fc.Stop()
+	select {
+	case <-done:
+	default:
+		t.Errorf("done channel not closed for a function cancelled by Stop")
+	}
+}
+
+func TestSimultaneousSleepersFireInCreationOrder(t *testing.T) {
+	t.Parallel()
+	// Run each callback as it is started, so the calls happen in the order
+	// the clock starts them.
+	fc := NewFakeClock(WithCallbackRunner(func(f func()) { f() }))
+
+	var order []int
+	for id := 1; id <= 3; id++ {
+		id := id
+		fc.AfterFunc(time.Second, func() { order = append(order, id) })
+	}
+
+	fc.Advance(time.Second)
+	want := []int{1, 2, 3}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("got callback order %v, want %v", order, want)
+	}
+}
+
+func TestIsActive(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+
+	timer := fc.NewTimer(time.Second)
+	if !IsActive(timer) {
+		t.Errorf("IsActive(pending timer) = false, want true")
+	}
+	timer.Stop()
+	if IsActive(timer) {
+		t.Errorf("IsActive(stopped timer) = true, want false")
+	}
+	timer.Reset(time.Second)
+	if !IsActive(timer) {
+		t.Errorf("IsActive(reset timer) = false, want true")
+	}
+	timer.(PausableTimer).Pause()
+	if !IsActive(timer) {
+		t.Errorf("IsActive(paused timer) = false, want true")
+	}
+	timer.(PausableTimer).Resume()
+	fc.Advance(time.Second)
+	if IsActive(timer) {
+		t.Errorf("IsActive(fired timer) = true, want false")
+	}
+
+	done, _ := fc.AfterFuncDone(time.Second, func() {})
+	if !IsActive(done) {
+		t.Errorf("IsActive(pending AfterFuncDone timer) = false, want true")
+	}
+	done.Stop()
+	if IsActive(done) {
+		t.Errorf("IsActive(stopped AfterFuncDone timer) = true, want false")
+	}
+	deadline := NewDeadlineTimer(context.Background(), fc, fc.Now().Add(time.Second))
+	if !IsActive(deadline) {
+		t.Errorf("IsActive(pending deadline timer) = false, want true")
+	}
+	deadline.Stop()
+	if IsActive(deadline) {
+		t.Errorf("IsActive(stopped deadline timer) = true, want false")
+	}
+
+	rt := NewRealClock().NewTimer(time.Hour)
+	defer rt.Stop()
+	if IsActive(rt) {
+		t.Errorf("IsActive(real timer) = true, want false")
+	}
+}
+
+type fakeWrapper struct {
+	Clock
+	fc FakeClock
+}
+
+func (fw fakeWrapper) Fake() FakeClock { return fw.fc }
+
+func TestAsFake(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	for _, test := range []struct {
+		name  string
+		clock Clock
+		want  FakeClock
+	}{
+		{name: "fake", clock: fc, want: fc},
+		{name: "real", clock: NewRealClock()},
+		{name: "plain wrapper", clock: struct{ Clock }{fc}},
+		{name: "FakeWrapper", clock: fakeWrapper{fc, fc}, want: fc},
+		{name: "decorator", clock: NewFreezableClock(fc), want: fc},
+		{name: "decorated real", clock: NewFreezableClock(NewRealClock())},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := AsFake(test.clock)
+			if got != test.want || ok != (test.want != nil) {
+				t.Errorf("AsFake() = %v, %v, want %v, %v", got, ok, test.want, test.want != nil)
+			}
+			if IsFake(test.clock) != ok {
+				t.Errorf("IsFake() = %v, want %v", !ok, ok)
+			}
+		})
+	}
+}
+
+func TestBase(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	stacked := NewJitterClock(NewFreezableClock(fc), rand.New(rand.NewSource(1)), 0.1)
+	if got := Base(stacked); got != fc {
+		t.Errorf("Base() = %v, want the fake clock %v", got, fc)
+	}
+	if got, ok := AsFake(stacked); got != fc || !ok {
+		t.Errorf("AsFake() = %v, %v, want %v, true", got, ok, fc)
+	}
+	if got := Base(fc); got != fc {
+		t.Errorf("Base() of an undecorated clock = %v, want it unchanged", got)
+	}
+}
+
+func TestFakeClockNextMidnight(t *testing.T) {
+	t.Parallel()
+	nyc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	for _, test := range []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{
+			name: "one minute to midnight",
+			now:  time.Date(2020, 1, 1, 23, 59, 0, 0, time.UTC),
+			want: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "exactly midnight",
+			now:  time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "end of year",
+			now:  time.Date(2020, 12, 31, 12, 0, 0, 0, time.UTC),
+			want: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "location aware",
+			now:  time.Date(2020, 1, 1, 23, 59, 0, 0, nyc),
+			want: time.Date(2020, 1, 2, 0, 0, 0, 0, nyc),
+		},
+		{
+			name: "across daylight saving change",
+			now:  time.Date(2020, 3, 7, 12, 0, 0, 0, nyc),
+			want: time.Date(2020, 3, 8, 0, 0, 0, 0, nyc),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fc := NewFakeClockAt(test.now)
+			if got := fc.NextMidnight(); !got.Equal(test.want) || got.Location() != test.want.Location() {
+				t.Errorf("NextMidnight() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNowUnix(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClockAt(time.Date(2020, 1, 1, 0, 0, 0, 123456789, time.UTC))
+	for i := 0; i < 3; i++ {
+		now := fc.Now()
+		if got, want := fc.NowUnixNano(), now.UnixNano(); got != want {
+			t.Errorf("NowUnixNano() = %d, want %d", got, want)
+		}
+		if got, want := fc.NowUnixMilli(), now.UnixNano()/1e6; got != want {
+			t.Errorf("NowUnixMilli() = %d, want %d", got, want)
+		}
+		if got, want := fc.NowUnix(), now.Unix(); got != want {
+			t.Errorf("NowUnix() = %d, want %d", got, want)
+		}
+		fc.Advance(1500 * time.Millisecond)
+	}
+
+	// Before the epoch, milliseconds round down like time.Time.UnixMilli.
+	fc.Set(time.Unix(-1, 400*1e6))
+	if got := fc.NowUnixMilli(); got != -600 {
+		t.Errorf("NowUnixMilli() = %d before the epoch, want -600", got)
+	}
+	if got := NewFreezableClock(fc).NowUnixMilli(); got != -600 {
+		t.Errorf("FreezableClock NowUnixMilli() = %d, want -600", got)
+	}
+
+	rc := NewRealClock()
+	before := time.Now().UnixNano()
+	if got := rc.NowUnixNano(); got < before {
+		t.Errorf("real NowUnixNano() = %d, before %d", got, before)
+	}
+}
+
+func TestAfterString(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	ch, err := fc.AfterString("1h30m")
+	if err != nil {
+		t.Fatalf("AfterString(%q) error: %v", "1h30m", err)
+	}
+	timer, err := fc.NewTimerString("2h")
+	if err != nil {
+		t.Fatalf("NewTimerString(%q) error: %v", "2h", err)
+	}
+	fc.Advance(90*time.Minute - 1)
+	select {
+	case <-ch:
+		t.Fatalf("AfterString channel fired early")
+	default:
+	}
+	fc.Advance(1)
+	select {
+	case <-ch:
+	default:
+		t.Errorf("AfterString channel did not fire after 1h30m")
+	}
+	fc.Advance(30 * time.Minute)
+	select {
+	case <-timer.C():
+	default:
+		t.Errorf("NewTimerString timer did not fire after 2h")
+	}
+
+	// Invalid strings schedule nothing.
+	for _, s := range []string{"", "1x", "fast"} {
+		if ch, err := fc.AfterString(s); err == nil || ch != nil {
+			t.Errorf("AfterString(%q) = %v, %v, want a parse error", s, ch, err)
+		}
+		if timer, err := fc.NewTimerString(s); err == nil || timer != nil {
+			t.Errorf("NewTimerString(%q) = %v, %v, want a parse error", s, timer, err)
+		}
+	}
+	fc.BlockUntil(0)
+
+	rc := NewRealClock()
+	if ch, err := rc.AfterString("1ms"); err != nil {
+		t.Errorf("real AfterString(%q) error: %v", "1ms", err)
+	} else {
+		<-ch
+	}
+	if _, err := rc.NewTimerString("1x"); err == nil {
+		t.Errorf("real NewTimerString(%q) returned no error", "1x")
+	}
+}
+
+// TestClose checks for leaked goroutines, so it doesn't run in parallel with
+// other tests.
+func TestClose(t *testing.T) {
+	before := runtime.NumGoroutine()
+	fc := NewFakeClock()
+	for i := 0; i < 5; i++ {
+		fc.NewTicker(time.Second)
+		fc.NewBurstTicker(time.Second, 2)
+	}
+	fc.NewStepTicker()
+	fc.NewTimer(time.Second)
+	fc.After(time.Hour)
+	slept := make(chan struct{})
+	go func() {
+		defer close(slept)
+		fc.Sleep(time.Hour)
+	}()
+	fc.BlockUntil(13)
+
+	tickers, sleepers := fc.Close()
+	if tickers != 11 || sleepers != 3 {
+		t.Errorf("Close() = %d, %d, want 11, 3", tickers, sleepers)
+	}
+	fc.BlockUntil(0)
+	select {
+	case <-slept:
+	case <-time.After(time.Second):
+		t.Fatalf("Sleep did not return after Close")
+	}
+	for deadline := time.Now().Add(time.Second); runtime.NumGoroutine() > before; {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines running after Close, want at most %d", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if tickers, sleepers := fc.Close(); tickers != 0 || sleepers != 0 {
+		t.Errorf("second Close() = %d, %d, want 0, 0", tickers, sleepers)
+	}
+
+	// Unlike after Stop, the clock is still usable.
+	timer := fc.NewTimer(time.Second)
+	fc.Advance(time.Second)
+	select {
+	case <-timer.C():
+	default:
+		t.Errorf("timer created after Close did not fire")
+	}
+}
+
+func TestCloseWakesUnbufferedSleep(t *testing.T) {
+	t.Parallel()
+	// With unbuffered timers a send to the sleeper's channel could be lost,
+	// so Close must still release the sleeping goroutine.
+	fc := NewFakeClock(WithUnbufferedTimers())
+	slept := make(chan struct{})
+	go func() {
+		defer close(slept)
+		fc.Sleep(time.Hour)
+	}()
+	fc.BlockUntil(1)
+
+	if _, sleepers := fc.Close(); sleepers != 1 {
+		t.Errorf("Close() stopped %d sleepers, want 1", sleepers)
+	}
+	withTimeout(t, time.Second, func() { <-slept })
+}
+
+func TestSinceUntilSaturate(t *testing.T) {
+	t.Parallel()
+	const (
+		maxDuration time.Duration = 1<<63 - 1
+		minDuration time.Duration = -1 << 63
+	)
+	now := time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)
+	long := time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)
+	fc := NewFakeClockAt(now)
+	clocks := map[string]Clock{
+		"fake":      fc,
+		"freezable": NewFreezableClock(fc),
+		"func":      FromNowFunc(fc.Now),
+	}
+	for name, c := range clocks {
+		if got := c.Since(long); got != maxDuration {
+			t.Errorf("%s: Since(year 1) = %v, want %v", name, got, maxDuration)
+		}
+		if got := c.Until(long); got != minDuration {
+			t.Errorf("%s: Until(year 1) = %v, want %v", name, got, minDuration)
+		}
+	}
+
+	fc.Set(long)
+	if got := fc.Since(now); got != minDuration {
+		t.Errorf("Since(year 10000) from year 1 = %v, want %v", got, minDuration)
+	}
+	if got := fc.Until(now); got != maxDuration {
+		t.Errorf("Until(year 10000) from year 1 = %v, want %v", got, maxDuration)
+	}
+}
+
+func TestSinceMixingClocks(t *testing.T) {
+	t.Parallel()
+	realNow := time.Now()
+	if realNow == realNow.Round(0) {
+		t.Skip("time.Now carries no monotonic reading on this platform")
+	}
+	fc := NewFakeClockAt(realNow.Add(time.Hour))
+	if now := fc.Now(); now != now.Round(0) {
+		t.Errorf("Now() = %v carries a monotonic reading", now)
+	}
+	fc = NewFakeClockNow()
+	if now := fc.Now(); now != now.Round(0) {
+		t.Errorf("NewFakeClockNow().Now() = %v carries a monotonic reading", now)
+	}
+
+	// A real time with a monotonic reading against a fake clock uses the wall
+	// clock, so the fake clock's time decides the result exactly.
+	fc = NewFakeClockAt(realNow.Round(0).Add(time.Hour))
+	if got := fc.Since(realNow); got != time.Hour {
+		t.Errorf("fake Since(real time) = %v, want %v", got, time.Hour)
+	}
+	if got := SinceMonotonic(fc, realNow); got != time.Hour {
+		t.Errorf("SinceMonotonic(fake, real time) = %v, want %v", got, time.Hour)
+	}
+
+	// A fake time against a real clock does too, and so does SinceMonotonic
+	// for two real times.
+	rc := NewRealClock()
+	fake := realNow.Round(0).Add(-time.Hour)
+	if got := rc.Since(fake); got < time.Hour || got > time.Hour+time.Minute {
+		t.Errorf("real Since(fake time) = %v, want just over %v", got, time.Hour)
+	}
+	if got := SinceMonotonic(rc, realNow.Add(-time.Hour)); got < time.Hour || got > time.Hour+time.Minute {
+		t.Errorf("SinceMonotonic(real, real time) = %v, want just over %v", got, time.Hour)
+	}
+}
+
+func TestFakeClockSinceAcrossLocations(t *testing.T) {
+	t.Parallel()
+	nyc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	// 01:30 EST, half an hour before clocks go forward to EDT.
+	start := time.Date(2020, 3, 8, 1, 30, 0, 0, nyc)
+	for _, opts := range [][]Option{nil, {WithResolution(time.Minute)}} {
+		fc := NewFakeClockAt(start, opts...)
+		fc.Advance(time.Hour)
+		if got := fc.Now(); got.Location() != nyc || got.Hour() != 3 {
+			t.Errorf("Now() = %v, want 03:30 EDT", got)
+		}
+		for _, since := range []time.Time{start, start.UTC(), start.In(time.FixedZone("UTC+13", 13*60*60))} {
+			if got := fc.Since(since); got != time.Hour {
+				t.Errorf("Since(%v) = %v, want %v", since, got, time.Hour)
+			}
+		}
+
+		// Moving the clock into another location doesn't change the
+		// elapsed time either.
+		fc.Set(fc.Now().UTC().Add(time.Hour))
+		if got := fc.Since(start); got != 2*time.Hour {
+			t.Errorf("Since(%v) after Set in UTC = %v, want %v", start, got, 2*time.Hour)
+		}
+	}
+}
+
+func TestClockDate(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	want := time.Date(2020, 2, 29, 12, 30, 0, 0, time.UTC)
+	if got := fc.Date(2020, 2, 29, 12, 30, 0, 0); !got.Equal(want) || got.Location() != time.UTC {
+		t.Errorf("fake Date() = %v, want %v", got, want)
+	}
+
+	if nyc, err := time.LoadLocation("America/New_York"); err == nil {
+		fc := NewFakeClockAt(time.Date(2020, 1, 1, 0, 0, 0, 0, nyc))
+		if got := fc.Date(2020, 7, 4, 9, 0, 0, 0); got.Location() != nyc {
+			t.Errorf("fake Date() in location %v, want %v", got.Location(), nyc)
+		}
+	}
+
+	if got := NewRealClock().Date(2020, 2, 29, 12, 30, 0, 0); got.Location() != time.Local {
+		t.Errorf("real Date() in location %v, want Local", got.Location())
+	}
+}
+
+func TestNowTruncatedRounded(t *testing.T) {
+	t.Parallel()
+	const bucket = time.Minute
+	edge := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		name      string
+		now       time.Time
+		truncated time.Time
+		rounded   time.Time
+	}{
+		{
+			name:      "at bucket edge",
+			now:       edge,
+			truncated: edge,
+			rounded:   edge,
+		},
+		{
+			name:      "one nanosecond before edge",
+			now:       edge.Add(-1),
+			truncated: edge.Add(-bucket),
+			rounded:   edge,
+		},
+		{
+			name:      "halfway through bucket",
+			now:       edge.Add(bucket / 2),
+			truncated: edge,
+			rounded:   edge.Add(bucket),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fc := NewFakeClockAt(test.now)
+			if got := fc.NowTruncated(bucket); !got.Equal(test.truncated) {
+				t.Errorf("NowTruncated() = %v, want %v", got, test.truncated)
+			}
+			if got := fc.NowRounded(bucket); !got.Equal(test.rounded) {
+				t.Errorf("NowRounded() = %v, want %v", got, test.rounded)
+			}
+
+			// A frozen clock buckets its frozen time.
+			c := NewFreezableClock(fc)
+			c.Freeze()
+			fc.Advance(bucket)
+			if got := c.NowTruncated(bucket); !got.Equal(test.truncated) {
+				t.Errorf("frozen NowTruncated() = %v, want %v", got, test.truncated)
+			}
+		})
+	}
+}
+
+func TestNewFakeClockWithEvents(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var order []string
+	event := func(at time.Duration, name string) ScheduledEvent {
+		return ScheduledEvent{
+			At:   start.Add(at),
+			Func: func() { order = append(order, name) },
+		}
+	}
+
+	// Deferring callbacks makes them run in due order on this goroutine.
+	fc := NewFakeClockWithEvents(start, []ScheduledEvent{
+		event(2*time.Second, "second"),
+		event(time.Second, "first"),
+		event(3*time.Second, "third"),
+	}, WithDeferredCallbacks())
+	if now := fc.Now(); !now.Equal(start) {
+		t.Errorf("clock starts at %v, want %v", now, start)
+	}
+	fc.BlockUntil(3)
+
+	fc.Advance(4 * time.Second)
+	if n := fc.RunPendingCallbacks(); n != 3 {
+		t.Errorf("RunPendingCallbacks() = %d, want 3", n)
+	}
+	if want := []string{"first", "second", "third"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("events ran in order %v, want %v", order, want)
+	}
+}
+
+func TestResetDiscardsStaleValue(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+
+	timer := fc.NewTimer(time.Second)
+	fc.Advance(time.Second)
+	// The first value is never read.
+	timer.Reset(time.Second)
+	fc.Advance(time.Second)
+
+	if n := len(timer.C()); n != 1 {
+		t.Fatalf("got %d values buffered, want 1", n)
+	}
+	if got, want := <-timer.C(), start.Add(2*time.Second); !got.Equal(want) {
+		t.Errorf("got value %v, want %v from the second expiry", got, want)
+	}
+}
+
+func TestResetDuringAdvance(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+
+	// However a Reset interleaves with an Advance firing the timer, the
+	// channel must never hold a value from before the Reset once it returns.
+	for i := 0; i < 1000; i++ {
+		timer := fc.NewTimer(time.Second)
+		advanced := make(chan struct{})
+		go func() {
+			defer close(advanced)
+			fc.Advance(time.Second)
+		}()
+		timer.Reset(time.Hour)
+		<-advanced
+		select {
+		case v := <-timer.C():
+			t.Fatalf("got stale value %v after Reset", v)
+		default:
+		}
+		timer.Stop()
+	}
+}
+
+func TestResetBaselineDuringAdvance(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	const step = time.Millisecond
+
+	var done uint32
+	advancing := make(chan struct{})
+	go func() {
+		defer close(advancing)
+		for atomic.LoadUint32(&done) == 0 {
+			fc.Advance(step)
+		}
+	}()
+
+	// Reset measures from a single clock time, one the clock actually held
+	// between the call and its return, however it races with Advance.
+	timer := fc.NewTimer(time.Hour)
+	for i := 0; i < 1000; i++ {
+		before := fc.Now()
+		timer.Reset(time.Hour)
+		deadline, _ := timer.Deadline()
+		after := fc.Now()
+		base := deadline.Add(-time.Hour)
+		if base.Before(before) || base.After(after) || base.Sub(start)%step != 0 {
+			t.Fatalf("Reset measured from %v, want a step between %v and %v", base.Sub(start), before.Sub(start), after.Sub(start))
+		}
+	}
+	atomic.StoreUint32(&done, 1)
+	<-advancing
+
+	// The timer then fires exactly at its deadline.
+	deadline, ok := timer.Deadline()
+	if !ok {
+		t.Fatalf("timer not pending after Reset")
+	}
+	fc.Advance(deadline.Sub(fc.Now()) - 1)
+	select {
+	case v := <-timer.C():
+		t.Fatalf("timer fired at %v, before its deadline %v", v, deadline)
+	default:
+	}
+	fc.Advance(1)
+	if v := <-timer.C(); !v.Equal(deadline) {
+		t.Errorf("timer fired at %v, want %v", v, deadline)
+	}
+}
+
+func TestConcurrentAdvance(t *testing.T) {
+	t.Parallel()
+	// The race detector needs contention rather than volume, so a few
+	// thousand advances per goroutine are enough.
+	const (
+		goroutines = 4
+		advances   = 5000
+	)
+	total := goroutines * advances
+	fc := NewFakeClock().(*fakeClock)
+	start := fc.Now()
+
+	// Spread sleepers over the whole range, counting how often each fires.
+	fired := make([]uint32, 10)
+	for i := range fired {
+		fc.addTimer(&sleeper{
+			fc:       fc,
+			until:    start.Add(time.Duration((i + 1) * total / len(fired))),
+			callback: func(arg interface{}, _ time.Time) { atomic.AddUint32(arg.(*uint32), 1) },
+			arg:      &fired[i],
+		})
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < advances; i++ {
+				fc.Advance(time.Nanosecond)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := fc.Since(start), time.Duration(total); got != want {
+		t.Errorf("clock advanced by %v, want %v", got, want)
+	}
+	for i := range fired {
+		if n := atomic.LoadUint32(&fired[i]); n != 1 {
+			t.Errorf("sleeper %d fired %d times, want 1", i, n)
+		}
+	}
+}
+
+func TestStopDuringCallback(t *testing.T) {
+	t.Parallel()
+	withTimeout(t, 100*time.Millisecond, func() {
+		fc := &fakeClock{}
+		other := fc.NewTimer(2)
+
+		var stopped bool
+		fc.addTimer(&sleeper{
+			fc:    fc,
+			until: fc.Now().Add(1),
+			callback: func(interface{}, time.Time) {
+				// Both calls need fc.l, which must not be held while
+				// callbacks run.
+				stopped = other.Stop()
+				fc.NewTimer(1)
+			},
+		})
+
+		fc.Advance(1)
+		if !stopped {
+			t.Errorf("Stop during callback returned false for an active timer")
+		}
+		fc.BlockUntil(1)
+	})
+}
+
+func TestAfterFuncDone(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name  string
+		clock Clock
+		wait  func(Clock)
+	}{
+		{
+			name:  "fake",
+			clock: NewFakeClock(),
+			wait:  func(c Clock) { c.(FakeClock).Advance(time.Second) },
+		},
+		{
+			name:  "real",
+			clock: NewRealClock(),
+			wait:  func(Clock) {},
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var ran uint32
+			_, done := test.clock.AfterFuncDone(time.Millisecond, func() {
+				atomic.StoreUint32(&ran, 1)
+			})
+			test.wait(test.clock)
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatalf("done was not closed after the callback ran")
+			}
+			if atomic.LoadUint32(&ran) != 1 {
+				t.Errorf("done was closed before the callback returned")
+			}
+
+			timer, done := test.clock.AfterFuncDone(time.Hour, func() {
+				t.Errorf("stopped callback ran")
+			})
+			if !timer.Stop() {
+				t.Fatalf("Stop() = false for a pending callback")
+			}
+			select {
+			case <-done:
+			default:
+				t.Errorf("done was not closed by Stop")
+			}
+		})
+	}
+}
+
+func TestAdvanceAndWait(t *testing.T) {
+	t.Parallel()
+	withTimeout(t, time.Second, func() {
+		fc := NewFakeClock()
+
+		var ran []int
+		var mu sync.Mutex
+		for i := 1; i <= 3; i++ {
+			i := i
+			fc.AfterFunc(time.Duration(i)*time.Second, func() {
+				mu.Lock()
+				defer mu.Unlock()
+				ran = append(ran, i)
+			})
+		}
+
+		fc.AdvanceAndWait(2 * time.Second)
+		// No synchronization beyond AdvanceAndWait is needed to observe the
+		// callbacks' effects.
+		if len(ran) != 2 {
+			t.Errorf("got %d callbacks run, want 2", len(ran))
+		}
+
+		fc.AdvanceAndWait(time.Second)
+		if len(ran) != 3 {
+			t.Errorf("got %d callbacks run, want 3", len(ran))
+		}
+	})
+}
+
+func TestAdvanceSafe(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	boom := errors.New("boom")
+	var ran []int
+	fc.AfterFunc(time.Second, func() { ran = append(ran, 1) })
+	fc.AfterFunc(2*time.Second, func() { panic(boom) })
+	fc.AfterFunc(2*time.Second, func() { panic("second") })
+	fc.AfterFunc(3*time.Second, func() { ran = append(ran, 3) })
+
+	if err := fc.AdvanceSafe(time.Second); err != nil {
+		t.Fatalf("AdvanceSafe() = %v with no panics", err)
+	}
+	// The function ran before AdvanceSafe returned.
+	if len(ran) != 1 {
+		t.Fatalf("got %d functions run, want 1", len(ran))
+	}
+
+	err := fc.AdvanceSafe(2 * time.Second)
+	perr, ok := err.(*CallbackPanicError)
+	if !ok {
+		t.Fatalf("AdvanceSafe() = %v, want a *CallbackPanicError", err)
+	}
+	if perr.Value != boom || perr.Label != "AfterFunc" || len(perr.Stack) == 0 {
+		t.Errorf("AdvanceSafe() = %+v, want the first panic, from AfterFunc, with a stack", perr)
+	}
+	if got := perr.Unwrap(); got != boom {
+		t.Errorf("Unwrap() = %v, want %v", got, boom)
+	}
+	if !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Error() = %q, want it to contain the panic value", err.Error())
+	}
+	if len(ran) != 2 {
+		t.Errorf("got %d functions run, want 2, as a panic doesn't stop the rest", len(ran))
+	}
+}
+
+func TestNonPositiveDurations(t *testing.T) {
+	t.Parallel()
+	for _, d := range []time.Duration{0, -1, -time.Hour} {
+		fc := NewFakeClock()
+
+		select {
+		case got := <-fc.After(d):
+			if !got.Equal(fc.Now()) {
+				t.Errorf("After(%v) sent %v, want %v", d, got, fc.Now())
+			}
+		default:
+			t.Errorf("After(%v) did not fire on creation", d)
+		}
+
+		timer := fc.NewTimer(d)
+		if len(timer.C()) != 1 {
+			t.Errorf("NewTimer(%v) did not buffer a value on creation", d)
+		}
+		if timer.Stop() {
+			t.Errorf("NewTimer(%v).Stop() = true, want false", d)
+		}
+
+		ran := make(chan struct{})
+		timer = fc.AfterFunc(d, func() { close(ran) })
+		select {
+		case <-ran:
+		case <-time.After(time.Second):
+			t.Errorf("AfterFunc(%v) did not run on creation", d)
+		}
+		if timer.Stop() {
+			t.Errorf("AfterFunc(%v).Stop() = true, want false", d)
+		}
+
+		withTimeout(t, 100*time.Millisecond, func() { fc.Sleep(d) })
+		fc.BlockUntil(0)
+	}
+}
+
+func TestAdvanceUntil(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClockAt(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	nineAM := func(now time.Time) bool { return now.Hour() >= 9 }
+
+	// A job scheduled for 09:00, with a sleeper on the way.
+	job := fc.After(9 * time.Hour)
+	early := fc.After(90 * time.Minute)
+
+	if err := fc.AdvanceUntil(nineAM, time.Minute, 1000); err != nil {
+		t.Fatalf("AdvanceUntil() returned error: %v", err)
+	}
+	if want := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC); !fc.Now().Equal(want) {
+		t.Errorf("clock stopped at %v, want %v", fc.Now(), want)
+	}
+	for name, c := range map[string]<-chan time.Time{"job": job, "early": early} {
+		select {
+		case <-c:
+		default:
+			t.Errorf("%s sleeper did not fire", name)
+		}
+	}
+
+	// Already satisfied: no steps are taken.
+	start := fc.Now()
+	if err := fc.AdvanceUntil(nineAM, time.Minute, 0); err != nil {
+		t.Errorf("AdvanceUntil() with satisfied predicate returned error: %v", err)
+	}
+	if !fc.Now().Equal(start) {
+		t.Errorf("AdvanceUntil() with satisfied predicate moved the clock")
+	}
+
+	never := func(time.Time) bool { return false }
+	if err := fc.AdvanceUntil(never, time.Second, 10); err == nil {
+		t.Errorf("AdvanceUntil() with unsatisfiable predicate returned nil error")
+	}
+	if got, want := fc.Since(start), 10*time.Second; got != want {
+		t.Errorf("AdvanceUntil() advanced %v before giving up, want %v", got, want)
+	}
+}
+
+func TestFakeClockNewTimerAt(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name       string
+		at         time.Duration // relative to the clock's current time
+		firesAfter time.Duration // advance needed before it fires
+	}{
+		{name: "past", at: -time.Hour},
+		{name: "now", at: 0},
+		{name: "future", at: time.Hour, firesAfter: time.Hour},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fc := NewFakeClock()
+			at := fc.Now().Add(test.at)
+			timer := fc.NewTimerAt(at)
+			after := fc.AfterAt(at)
+
+			if test.firesAfter > 0 {
+				fc.Advance(test.firesAfter - 1)
+				if len(timer.C()) != 0 || len(after) != 0 {
+					t.Fatalf("fired before reaching %v", at)
+				}
+				fc.Advance(1)
+			}
+			for name, c := range map[string]<-chan time.Time{"NewTimerAt": timer.C(), "AfterAt": after} {
+				select {
+				case got := <-c:
+					if !got.Equal(fc.Now()) {
+						t.Errorf("%s sent %v, want %v", name, got, fc.Now())
+					}
+				default:
+					t.Errorf("%s did not fire", name)
+				}
+			}
+		})
+	}
+}
+
+func TestRealClockNewTimerAt(t *testing.T) {
+	t.Parallel()
+	rc := NewRealClock()
+	at := rc.Now().Add(10 * time.Millisecond)
+	timer := rc.NewTimerAt(at)
+	for name, c := range map[string]<-chan time.Time{"NewTimerAt": timer.C(), "AfterAt": rc.AfterAt(at)} {
+		select {
+		case got := <-c:
+			if got.Before(at) {
+				t.Errorf("%s fired at %v, before %v", name, got, at)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("%s did not fire", name)
+		}
+	}
+}
+
+func TestSetToNow(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock().(*fakeClock)
+	now := fc.Now()
+	later := fc.After(time.Second)
+
+	// A sleeper exactly at the current instant which has not been notified
+	// yet. Timers created through the API fire immediately instead, so it is
+	// registered directly.
+	due := make(chan time.Time, 1)
+	fc.l.Lock()
+	fc.sleepers = append(fc.sleepers, &sleeper{fc: fc, until: now, callback: sendTime, arg: due, ch: due})
+	fc.l.Unlock()
+
+	fc.Set(now)
+	if got := fc.Now(); !got.Equal(now) {
+		t.Errorf("Set(Now()) changed the time to %v, want %v", got, now)
+	}
+	select {
+	case got := <-due:
+		if !got.Equal(now) {
+			t.Errorf("sleeper at the current instant got %v, want %v", got, now)
+		}
+	default:
+		t.Errorf("sleeper at the current instant was not notified")
+	}
+	select {
+	case <-later:
+		t.Errorf("future sleeper was notified by Set(Now())")
+	default:
+	}
+	fc.BlockUntil(1)
+
+	fc.Set(now)
+	if got := fc.Now(); !got.Equal(now) {
+		t.Errorf("repeated Set(Now()) changed the time to %v, want %v", got, now)
+	}
+}
+
+func BenchmarkFakeClockNow(b *testing.B) {
+	fc := NewFakeClock()
+	for i := 0; i < b.N; i++ {
+		fc.Now()
+	}
+}
+
+func BenchmarkFakeClockNowParallel(b *testing.B) {
+	fc := NewFakeClock()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			fc.Now()
+		}
+	})
+}
+
+func TestResetRegistersOnce(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock().(*fakeClock)
+	timer := fc.NewTimer(time.Second)
+
+	for i := 0; i < 1000; i++ {
+		timer.Reset(time.Duration(i+1) * time.Millisecond)
+	}
+	if n := len(fc.sleepers); n != 1 {
+		t.Fatalf("got %d sleepers after repeated Reset, want 1", n)
+	}
+
+	// Concurrent Resets mustn't register the timer twice either.
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 250; i++ {
+				timer.Reset(time.Second)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := len(fc.sleepers); n != 1 {
+		t.Fatalf("got %d sleepers after concurrent Reset, want 1", n)
+	}
+
+	fc.Advance(time.Second)
+	if n := len(fc.sleepers); n != 0 {
+		t.Errorf("got %d sleepers after firing, want 0", n)
+	}
+	select {
+	case <-timer.C():
+	default:
+		t.Errorf("reset timer did not fire")
+	}
+}
+
+func TestStopRemovesSleeperEagerly(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock().(*fakeClock)
+
+	var timers []Timer
+	for i := 0; i < 100; i++ {
+		timers = append(timers, fc.AfterFunc(time.Hour, func() {}))
+		timers = append(timers, fc.NewTimer(time.Hour))
+	}
+	for i, timer := range timers {
+		timer.Stop()
+		if got, want := len(fc.sleepers), len(timers)-i-1; got != want {
+			t.Fatalf("got %d sleepers after %d Stops, want %d", got, i+1, want)
+		}
+	}
+	for i, s := range fc.sleepers[:cap(fc.sleepers)] {
+		if s != nil {
+			t.Fatalf("backing array still references stopped sleeper %d", i)
+		}
+	}
+}
+
+func TestScope(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	outer := fc.After(time.Hour)
+
+	var inner Timer
+	fc.Scope(func() {
+		inner = fc.NewTimer(time.Minute)
+		fired := fc.After(time.Second)
+		fc.Advance(time.Second)
+		select {
+		case <-fired:
+		default:
+			t.Errorf("sleeper did not fire inside scope")
+		}
+		if got := fc.Since(start); got != time.Second {
+			t.Errorf("clock inside scope advanced %v, want 1s", got)
+		}
+	})
+
+	if now := fc.Now(); !now.Equal(start) {
+		t.Errorf("Scope did not restore time: got %v, want %v", now, start)
+	}
+	if IsActive(inner) {
+		t.Errorf("sleeper created inside scope is still pending")
+	}
+	fc.BlockUntil(1)
+
+	fc.Advance(time.Hour)
+	select {
+	case <-outer:
+	default:
+		t.Errorf("sleeper created before scope did not fire")
+	}
+	select {
+	case <-inner.C():
+		t.Errorf("sleeper created inside scope fired after it")
+	default:
+	}
+}
+
+func TestScopeStopsTickersAndWakesSleepers(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+
+	outer := fc.NewStepTicker()
+	defer outer.Stop()
+	var ticker, step Ticker
+	slept := make(chan struct{})
+	fc.Scope(func() {
+		ticker = fc.NewTicker(time.Second)
+		step = fc.NewStepTicker()
+		go func() {
+			fc.Sleep(time.Hour)
+			close(slept)
+		}()
+		fc.BlockUntil(2)
+	})
+
+	withTimeout(t, time.Second, func() { <-slept })
+	ft := ticker.(*fakeTicker)
+	ft.l.Lock()
+	stopped := ft.stopped
+	ft.l.Unlock()
+	if !stopped {
+		t.Errorf("ticker created inside scope is not marked stopped")
+	}
+	if n := len(fc.(*fakeClock).tickers); n != 0 {
+		t.Errorf("%d tickers still registered after scope", n)
+	}
+	if n := fc.ActiveTickers(); n != 1 {
+		t.Errorf("ActiveTickers() = %d after scope, want only the outer step ticker", n)
+	}
+	fc.Advance(time.Second)
+	select {
+	case tick := <-step.Chan():
+		t.Errorf("step ticker created inside scope ticked at %v after it", tick)
+	default:
+	}
+}
+
+func TestBlockers(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	if got := fc.Blockers(); len(got) != 0 {
+		t.Errorf("Blockers() = %v for a new clock, want none", got)
+	}
+
+	var wg sync.WaitGroup
+	for _, n := range []int{2, 3} {
+		n := n
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fc.BlockUntil(n)
+		}()
+	}
+	for deadline := time.Now().Add(time.Second); len(fc.Blockers()) < 2; {
+		if time.Now().After(deadline) {
+			t.Fatalf("Blockers() = %v, want two blockers", fc.Blockers())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	got := fc.Blockers()
+	sort.Ints(got)
+	if want := []int{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Blockers() = %v, want %v", got, want)
+	}
+
+	for i := 0; i < 3; i++ {
+		fc.After(time.Second)
+	}
+	wg.Wait()
+	if got := fc.Blockers(); len(got) != 0 {
+		t.Errorf("Blockers() = %v once released, want none", got)
+	}
+}
+
+func TestLastBlockDuration(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	if d := fc.LastBlockDuration(); d != 0 {
+		t.Errorf("LastBlockDuration() = %v before any BlockUntil, want 0", d)
+	}
+
+	const delay = 20 * time.Millisecond
+	go func() {
+		time.Sleep(delay)
+		fc.After(time.Second)
+	}()
+	fc.BlockUntil(1)
+	if d := fc.LastBlockDuration(); d < delay {
+		t.Errorf("LastBlockDuration() = %v, want at least %v", d, delay)
+	}
+
+	// A BlockUntil which needn't wait records no time.
+	fc.BlockUntil(1)
+	if d := fc.LastBlockDuration(); d != 0 {
+		t.Errorf("LastBlockDuration() = %v without blocking, want 0", d)
+	}
+}
+
+func TestBlockUntilBlocked(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	const n = 3
+
+	// Sleepers nobody is blocked on don't count.
+	fc.After(time.Second)
+	fc.NewTimer(time.Second)
+
+	returned := make(chan struct{})
+	go func() {
+		fc.BlockUntilBlocked(n)
+		close(returned)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		select {
+		case <-returned:
+			t.Fatalf("BlockUntilBlocked(%d) returned with %d goroutines in Sleep", n, i)
+		case <-time.After(10 * time.Millisecond):
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fc.Sleep(time.Second)
+		}()
+	}
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatalf("BlockUntilBlocked(%d) did not return", n)
+	}
+
+	fc.Advance(time.Second)
+	withTimeout(t, time.Second, func() {
+		fc.BlockUntilBlocked(0)
+		wg.Wait()
+	})
+}
+
+func TestBlockUntilStress(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	iterations := 500
+	if testing.Short() {
+		iterations = 50
+	}
+
+	withTimeout(t, 20*time.Second, func() {
+		for i := 0; i < iterations; i++ {
+			k := i%4 + 1
+			var wg sync.WaitGroup
+			for j := 0; j < k; j++ {
+				wg.Add(1)
+				go func(j int) {
+					defer wg.Done()
+					fc.Sleep(time.Duration(j+1) * time.Millisecond)
+				}(j)
+			}
+			fc.BlockUntil(k)
+
+			// Timers are created and stopped, and sleepers fire one by one,
+			// while BlockUntil waits for them all to go.
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for j := 0; j < k; j++ {
+					fc.NewTimer(time.Hour).Stop()
+					fc.Advance(time.Millisecond)
+				}
+			}()
+			fc.BlockUntil(0)
+			<-done
+			wg.Wait()
+		}
+	})
+}
+
+func TestAdvanceSteps(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+
+	at1 := fc.After(time.Second)
+	at5a := fc.After(5 * time.Second)
+	at5b := fc.After(5 * time.Second)
+	at9 := fc.After(9 * time.Second)
+
+	fired := func(c <-chan time.Time) bool {
+		select {
+		case <-c:
+			return true
+		default:
+			return false
+		}
+	}
+
+	// Both sleepers due at +5s fire together, as the second step.
+	if n := fc.AdvanceSteps(2); n != 2 {
+		t.Errorf("AdvanceSteps(2) = %d, want 2", n)
+	}
+	if got := fc.Since(start); got != 5*time.Second {
+		t.Errorf("clock at +%v after two steps, want +5s", got)
+	}
+	if !fired(at1) || !fired(at5a) || !fired(at5b) {
+		t.Errorf("sleepers at +1s and +5s did not all fire")
+	}
+	if fired(at9) {
+		t.Errorf("sleeper at +9s fired early")
+	}
+
+	// Only one step is left to take.
+	if n := fc.AdvanceSteps(3); n != 1 {
+		t.Errorf("AdvanceSteps(3) = %d, want 1", n)
+	}
+	if got := fc.Since(start); got != 9*time.Second {
+		t.Errorf("clock at +%v after last step, want +9s", got)
+	}
+	if !fired(at9) {
+		t.Errorf("sleeper at +9s did not fire")
+	}
+}
+
+func TestAdvanceStepsEmpty(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	st := fc.NewStepTicker()
+	defer st.Stop()
+	hooked := false
+	fc.OnNextAdvance(func(from, to time.Time) { hooked = true })
+
+	if n := fc.AdvanceSteps(3); n != 0 {
+		t.Errorf("AdvanceSteps(3) = %d on an empty clock, want 0", n)
+	}
+	if !fc.Now().Equal(start) {
+		t.Errorf("AdvanceSteps moved an empty clock to %v", fc.Now())
+	}
+	select {
+	case tick := <-st.Chan():
+		t.Errorf("step ticker ticked at %v", tick)
+	default:
+	}
+	if hooked {
+		t.Errorf("AdvanceSteps ran an OnNextAdvance hook without moving")
+	}
+	if got := fc.Stats().AdvanceCalls; got != 0 {
+		t.Errorf("AdvanceSteps counted %d Advance calls, want 0", got)
+	}
+}
+
+func TestOnFire(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+
+	timer := fc.NewTimer(time.Second)
+	var fired []time.Duration
+	fc.OnFire(timer, func(at time.Time) { fired = append(fired, at.Sub(start)) })
+	fc.OnFire(timer, func(time.Time) { fired = append(fired, -1) })
+
+	fc.Advance(time.Second)
+	if want := []time.Duration{time.Second, -1}; !reflect.DeepEqual(fired, want) {
+		t.Errorf("observers called with %v, want %v", fired, want)
+	}
+	// The timer's own channel still receives its value.
+	if v := <-timer.C(); !v.Equal(start.Add(time.Second)) {
+		t.Errorf("timer fired at %v, want %v", v, start.Add(time.Second))
+	}
+
+	// Observers stay attached across Reset, and aren't called by Stop.
+	fired = nil
+	timer.Reset(time.Second)
+	timer.Stop()
+	fc.Advance(time.Second)
+	timer.Reset(time.Second)
+	fc.Advance(time.Second)
+	if want := []time.Duration{3 * time.Second, -1}; !reflect.DeepEqual(fired, want) {
+		t.Errorf("observers called with %v after Reset, want %v", fired, want)
+	}
+
+	var observed uint32
+	done := make(chan struct{})
+	af := fc.AfterFunc(time.Second, func() { close(done) })
+	fc.OnFire(af, func(time.Time) { atomic.StoreUint32(&observed, 1) })
+	fc.Advance(time.Second)
+	if atomic.LoadUint32(&observed) == 0 {
+		t.Errorf("AfterFunc observer not called before Advance returned")
+	}
+	<-done
+
+	mustPanic(t, "OnFire with another clock's timer", func() {
+		fc.OnFire(NewFakeClock().NewTimer(time.Second), func(time.Time) {})
+	})
+}
+
+func TestJump(t *testing.T) {
+	t.Parallel()
+	for _, d := range []time.Duration{time.Hour, -time.Hour} {
+		// Advancing past a timer fires it.
+		fc := NewFakeClock()
+		timer := fc.NewTimer(10 * time.Second)
+		fc.Advance(time.Hour)
+		if len(timer.C()) == 0 {
+			t.Errorf("Advance(%v) did not fire a 10s timer", time.Hour)
+		}
+
+		// Jumping doesn't, however far the clock moves.
+		fc = NewFakeClock()
+		start := fc.Now()
+		timer = fc.NewTimer(10 * time.Second)
+		ticker := fc.NewTicker(time.Second)
+		fc.Jump(d)
+		if got, want := fc.Now(), start.Add(d); !got.Equal(want) {
+			t.Errorf("Jump(%v): Now() = %v, want %v", d, got, want)
+		}
+		if len(timer.C()) != 0 || len(ticker.Chan()) != 0 {
+			t.Errorf("Jump(%v) fired a timer or ticker", d)
+		}
+		if deadline, _ := timer.Deadline(); !deadline.Equal(start.Add(d + 10*time.Second)) {
+			t.Errorf("Jump(%v): timer deadline %v, want %v", d, deadline, start.Add(d+10*time.Second))
+		}
+
+		// Timers still fire after the same amount of Advance as before.
+		fc.Advance(time.Second)
+		if tick := <-ticker.Chan(); !tick.Equal(start.Add(d + time.Second)) {
+			t.Errorf("Jump(%v): ticked at %v, want %v", d, tick, start.Add(d+time.Second))
+		}
+		fc.Advance(9*time.Second - 1)
+		if len(timer.C()) != 0 {
+			t.Errorf("Jump(%v): timer fired early", d)
+		}
+		fc.Advance(1)
+		if len(timer.C()) == 0 {
+			t.Errorf("Jump(%v): timer did not fire after 10s", d)
+		}
+		ticker.Stop()
+	}
+}
+
+func TestJumpSkewedTicker(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	// Each tick is a tenth of a second later than the one before.
+	ticker := fc.NewSkewedTicker(time.Second, func(n int) time.Duration {
+		return time.Duration(n) * 100 * time.Millisecond
+	})
+	defer ticker.Stop()
+
+	fc.Jump(time.Hour)
+	for _, want := range []time.Duration{1100, 2200, 3300} {
+		want := start.Add(time.Hour + want*time.Millisecond)
+		if tick := fc.WaitTick(ticker); !tick.Equal(want) {
+			t.Errorf("tick after Jump at %v, want %v", tick, want)
+		}
+	}
+}
+
+func TestJumpDeadlineContext(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	want := fc.Now().Add(time.Hour + 10*time.Second)
+	ctx, cancel := fc.DeadlineContext(context.Background(), fc.Now().Add(10*time.Second))
+	defer cancel()
+
+	fc.Jump(time.Hour)
+	if deadline, _ := ctx.Deadline(); !deadline.Equal(want) {
+		t.Errorf("Deadline() after Jump = %v, want %v", deadline, want)
+	}
+	fc.Advance(10*time.Second - 1)
+	if err := ctx.Err(); err != nil {
+		t.Errorf("context done before its jumped deadline: %v", err)
+	}
+	fc.Advance(1)
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Errorf("Err() at the jumped deadline = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWallAndMonotonic(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	timer := fc.NewTimer(10 * time.Second)
+
+	// An NTP correction sets the wall clock back an hour, while the
+	// monotonic clock keeps moving forward.
+	fc.AdvanceMonotonic(4 * time.Second)
+	fc.SetWall(start.Add(-time.Hour))
+	fc.AdvanceMonotonic(5 * time.Second)
+	if got, want := fc.Now(), start.Add(-time.Hour+5*time.Second); !got.Equal(want) {
+		t.Errorf("Now() = %v, want %v", got, want)
+	}
+	if got := fc.Monotonic(); got != 9*time.Second {
+		t.Errorf("Monotonic() = %v, want 9s", got)
+	}
+	if len(timer.C()) != 0 {
+		t.Fatalf("timer fired after 9s of monotonic time")
+	}
+
+	// The timer fires after 10s of monotonic time, at the wall time then.
+	fc.AdvanceMonotonic(time.Second)
+	select {
+	case got := <-timer.C():
+		if want := start.Add(-time.Hour + 6*time.Second); !got.Equal(want) {
+			t.Errorf("timer fired at wall time %v, want %v", got, want)
+		}
+	default:
+		t.Errorf("timer did not fire after 10s of monotonic time")
+	}
+
+	// Set backward doesn't move the monotonic clock; Set forward does.
+	fc.Set(fc.Now().Add(-time.Minute))
+	fc.Set(fc.Now().Add(time.Second))
+	if got := fc.Monotonic(); got != 11*time.Second {
+		t.Errorf("Monotonic() after Set = %v, want 11s", got)
+	}
+	mustPanicWith(t, "AdvanceMonotonic(-1)", ErrClockRewind, func() { fc.AdvanceMonotonic(-1) })
+}
+
+func TestAdvanceSequence(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	timers := []Timer{
+		fc.NewTimer(time.Second),
+		fc.NewTimer(3 * time.Second),
+		fc.NewTimer(8 * time.Second),
+	}
+
+	steps := 0
+	fc.AdvanceSequence(func(i int, now time.Time) {
+		steps++
+		if want := start.Add([]time.Duration{time.Second, 3 * time.Second, 8 * time.Second}[i]); !now.Equal(want) {
+			t.Errorf("step %d: now = %v, want %v", i, now, want)
+		}
+		// Step i fires timer i, and no later one.
+		for j, timer := range timers {
+			fired := len(timer.C()) > 0
+			if want := j == i; fired != want {
+				t.Errorf("step %d: timer %d fired = %v, want %v", i, j, fired, want)
+			}
+		}
+		<-timers[i].C()
+	}, time.Second, 2*time.Second, 5*time.Second)
+	if steps != 3 {
+		t.Errorf("onStep called %d times, want 3", steps)
+	}
+
+	// A nil onStep is allowed.
+	fc.AdvanceSequence(nil, time.Second, time.Second)
+	if got, want := fc.Now(), start.Add(10*time.Second); !got.Equal(want) {
+		t.Errorf("Now() = %v, want %v", got, want)
+	}
+}
+
+func TestAfterFuncStopAfterFiring(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name string
+		opts []Option
+	}{
+		{name: "goroutine"},
+		{name: "deferred", opts: []Option{WithDeferredCallbacks()}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fc := NewFakeClock(test.opts...)
+			ran := make(chan struct{})
+			timer := fc.AfterFunc(time.Second, func() { close(ran) })
+
+			// Stopping straight after the Advance which fired the timer,
+			// before its function has necessarily started, is too late.
+			fc.Advance(time.Second)
+			if timer.Stop() {
+				t.Errorf("Stop() = true after the timer fired")
+			}
+			fc.RunPendingCallbacks()
+			select {
+			case <-ran:
+			case <-time.After(time.Second):
+				t.Fatalf("function did not run after Stop returned false")
+			}
+		})
+	}
+}
+
+func TestSleepUntil(t *testing.T) {
+	t.Parallel()
+	for name, offset := range map[string]time.Duration{
+		"past":   -time.Second,
+		"now":    0,
+		"future": time.Second,
+	} {
+		offset := offset
+		t.Run("fake "+name, func(t *testing.T) {
+			fc := NewFakeClock()
+			target := fc.Now().Add(offset)
+			done := make(chan struct{})
+			go func() {
+				fc.SleepUntil(target)
+				close(done)
+			}()
+			if offset > 0 {
+				fc.BlockUntilBlocked(1)
+				fc.Advance(offset - 1)
+				select {
+				case <-done:
+					t.Fatalf("SleepUntil returned before the target")
+				case <-time.After(10 * time.Millisecond):
+				}
+				fc.Advance(1)
+			}
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatalf("SleepUntil did not return")
+			}
+		})
+		t.Run("real "+name, func(t *testing.T) {
+			rc := NewRealClock()
+			target := rc.Now().Add(offset / 100)
+			rc.SleepUntil(target)
+			if now := rc.Now(); now.Before(target) {
+				t.Errorf("SleepUntil returned at %v, before %v", now, target)
+			}
+		})
+	}
+}
+
+func TestAfterFuncTimerChannelIsNil(t *testing.T) {
+	t.Parallel()
+	for name, c := range map[string]Clock{
+		"real": NewRealClock(),
+		"fake": NewFakeClock(),
+	} {
+		timer := c.AfterFunc(time.Hour, func() {})
+		if ch := timer.C(); ch != nil {
+			t.Errorf("%s: AfterFunc timer has channel %v, want nil", name, ch)
+		}
+		timer.Stop()
+	}
+}
+
+func TestZeroValueFakeClock(t *testing.T) {
+	t.Parallel()
+	withTimeout(t, time.Second, func() {
+		var fc fakeClock
+		if now := fc.Now(); !now.IsZero() {
+			t.Errorf("zero fakeClock Now() = %v, want the zero time", now)
+		}
+		if s := fc.Since(time.Time{}); s != 0 {
+			t.Errorf("zero fakeClock Since(zero) = %v, want 0", s)
+		}
+		fc.NextMidnight()
+		fc.Date(2000, 1, 1, 0, 0, 0, 0)
+
+		timer := fc.NewTimer(time.Second)
+		after := fc.After(time.Second)
+		ticker := fc.NewTicker(time.Second)
+		var ran int32
+		fc.AfterFunc(time.Second, func() { atomic.AddInt32(&ran, 1) })
+		fc.BlockUntil(4)
+		fc.BlockUntilBlocked(0)
+		snap := fc.Snapshot()
+
+		fc.AdvanceAndWait(time.Second)
+		<-timer.C()
+		<-after
+		<-ticker.Chan()
+		if atomic.LoadInt32(&ran) != 1 {
+			t.Errorf("AfterFunc function did not run")
+		}
+		ticker.Stop()
+
+		fc.Restore(snap)
+		fc.Scope(func() { fc.Advance(time.Second) })
+		fc.Set(time.Time{}.Add(time.Hour))
+		fc.AdvanceSteps(1)
+		fc.AdvanceAndYield(0)
+		if err := fc.AdvanceUntil(func(time.Time) bool { return true }, time.Second, 1); err != nil {
+			t.Errorf("AdvanceUntil: %v", err)
+		}
+		fc.TickerOverruns()
+		fc.Stats()
+		fc.RunPendingCallbacks()
+		fc.EventLog()
+		fc.Stop()
+		fc.Sleep(time.Second) // returns at once on a stopped clock
+	})
+}
+
+func TestTimerDeadline(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+
+	timer := fc.NewTimer(time.Second)
+	if got, ok := timer.Deadline(); !ok || !got.Equal(start.Add(time.Second)) {
+		t.Errorf("Deadline() = %v, %v, want %v, true", got, ok, start.Add(time.Second))
+	}
+	fc.Advance(500 * time.Millisecond)
+	timer.Reset(time.Second)
+	if got, ok := timer.Deadline(); !ok || !got.Equal(start.Add(1500*time.Millisecond)) {
+		t.Errorf("Deadline() after Reset = %v, %v, want %v, true", got, ok, start.Add(1500*time.Millisecond))
+	}
+	fc.Advance(time.Second)
+	if got, ok := timer.Deadline(); ok {
+		t.Errorf("Deadline() after firing = %v, true, want false", got)
+	}
+
+	stopped := fc.AfterFunc(time.Second, func() {})
+	stopped.Stop()
+	if got, ok := stopped.Deadline(); ok {
+		t.Errorf("Deadline() after Stop = %v, true, want false", got)
+	}
+
+	rt := NewRealClock().NewTimer(time.Hour)
+	defer rt.Stop()
+	if _, ok := rt.Deadline(); ok {
+		t.Errorf("real timer Deadline() reported a deadline")
+	}
+}
+
+func TestPausableTimer(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	timer, ok := fc.NewTimer(10 * time.Second).(PausableTimer)
+	if !ok {
+		t.Fatalf("fake Timer doesn't implement PausableTimer")
+	}
+	rt := NewRealClock().NewTimer(time.Hour)
+	defer rt.Stop()
+	if _, ok := rt.(PausableTimer); ok {
+		t.Errorf("real Timer implements PausableTimer")
+	}
+
+	fc.Advance(4 * time.Second)
+	if !timer.Pause() {
+		t.Errorf("Pause() = false for a pending timer")
+	}
+	if timer.Pause() {
+		t.Errorf("Pause() = true for a paused timer")
+	}
+	if _, ok := timer.Deadline(); ok {
+		t.Errorf("Deadline() reported a deadline for a paused timer")
+	}
+	fc.Advance(time.Minute)
+	select {
+	case <-timer.C():
+		t.Fatalf("paused timer fired")
+	default:
+	}
+
+	resumed := fc.Now()
+	if !timer.Resume() {
+		t.Errorf("Resume() = false for a paused timer")
+	}
+	if deadline, _ := timer.Deadline(); !deadline.Equal(resumed.Add(6 * time.Second)) {
+		t.Errorf("Deadline() after Resume = %v, want %v", deadline, resumed.Add(6*time.Second))
+	}
+	fc.Advance(6*time.Second - 1)
+	select {
+	case <-timer.C():
+		t.Fatalf("resumed timer fired before its remaining time passed")
+	default:
+	}
+	fc.Advance(1)
+	select {
+	case <-timer.C():
+	default:
+		t.Fatalf("resumed timer didn't fire once its remaining time passed")
+	}
+	if timer.Pause() || timer.Resume() {
+		t.Errorf("Pause or Resume succeeded on a fired timer")
+	}
+
+	// A paused timer counts as pending for Stop.
+	other := fc.AfterFunc(time.Second, func() { t.Errorf("stopped function ran") }).(PausableTimer)
+	other.Pause()
+	if !other.Stop() {
+		t.Errorf("Stop() = false for a paused timer")
+	}
+	if other.Resume() {
+		t.Errorf("Resume() = true for a stopped timer")
+	}
+	fc.Advance(time.Minute)
+}
+
+func TestStaleResets(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	timer := fc.NewTimer(time.Second)
+	timer.Reset(time.Second)
+	if n := fc.StaleResets(); n != 0 {
+		t.Errorf("StaleResets() = %d after resetting a pending timer, want 0", n)
+	}
+
+	fc.Advance(time.Second)
+	timer.Reset(time.Second) // without draining the value from the first expiry
+	if n := fc.StaleResets(); n != 1 {
+		t.Errorf("StaleResets() = %d after a stale Reset, want 1", n)
+	}
+
+	fc.Advance(time.Second)
+	if ResetDrained(timer, time.Second) {
+		t.Errorf("ResetDrained() = true for a timer which had fired")
+	}
+	if n := fc.StaleResets(); n != 1 {
+		t.Errorf("StaleResets() = %d after ResetDrained, want 1", n)
+	}
+	fc.Advance(time.Second)
+	<-timer.C()
+
+	fc = NewFakeClock(WithGo123Timers())
+	timer = fc.NewTimer(time.Second)
+	fc.Advance(time.Second)
+	timer.Reset(time.Second)
+	if n := fc.StaleResets(); n != 0 {
+		t.Errorf("StaleResets() = %d with WithGo123Timers, want 0", n)
+	}
+}
+
+func TestOnNextAdvance(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	var calls [][2]time.Time
+	var order []string
+	timer := fc.NewTimer(time.Second)
+	fc.OnNextAdvance(func(from, to time.Time) {
+		calls = append(calls, [2]time.Time{from, to})
+		// The timer due in this move has already fired.
+		select {
+		case <-timer.C():
+			order = append(order, "timer fired")
+		default:
+		}
+	})
+	fc.OnNextAdvance(func(from, to time.Time) { order = append(order, "second") })
+
+	fc.Advance(time.Second)
+	if len(calls) != 1 || !calls[0][0].Equal(start) || !calls[0][1].Equal(start.Add(time.Second)) {
+		t.Errorf("OnNextAdvance called with %v, want once with %v, %v", calls, start, start.Add(time.Second))
+	}
+	if want := []string{"timer fired", "second"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("got %v, want %v", order, want)
+	}
+
+	fc.Advance(time.Second)
+	fc.Set(start)
+	if len(calls) != 1 {
+		t.Errorf("OnNextAdvance function called %d times, want 1", len(calls))
+	}
+}
+
+func TestTimeRange(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	if _, _, ok := fc.TimeRange(); ok {
+		t.Errorf("TimeRange() reported a range with no sleepers")
+	}
+
+	start := fc.Now()
+	fc.NewTimer(5 * time.Second)
+	fc.AfterFunc(time.Second, func() {})
+	last := fc.NewTimer(time.Minute)
+	earliest, latest, ok := fc.TimeRange()
+	if !ok || !earliest.Equal(start.Add(time.Second)) || !latest.Equal(start.Add(time.Minute)) {
+		t.Errorf("TimeRange() = %v, %v, %v, want %v, %v, true", earliest, latest, ok, start.Add(time.Second), start.Add(time.Minute))
+	}
+
+	last.Stop()
+	fc.Advance(time.Second)
+	earliest, latest, ok = fc.TimeRange()
+	if !ok || !earliest.Equal(start.Add(5*time.Second)) || !latest.Equal(earliest) {
+		t.Errorf("TimeRange() = %v, %v, %v, want %v for both, true", earliest, latest, ok, start.Add(5*time.Second))
+	}
+}
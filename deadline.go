@@ -0,0 +1,144 @@
+package clockwork
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NewDeadlineTimer returns a Timer which fires when c reaches deadline, unless
+// ctx is done first, in which case the Timer is stopped and its channel stays
+// silent. It models a request deadline which is abandoned if the request is
+// cancelled.
+//
+// On a FakeClock the Timer fires like one from NewTimerAt: the time the clock
+// was moved to is sent before the Advance or Set which reaches deadline
+// returns. On other clocks it is built on AfterFunc, and sends c.Now() from
+// the function's goroutine.
+//
+// While the Timer is pending a goroutine watches ctx; it exits as soon as the
+// Timer fires or is stopped. Resetting the Timer watches ctx again, unless ctx
+// is already done, in which case Reset just stops it. T returns nil.
+func NewDeadlineTimer(ctx context.Context, c Clock, deadline time.Time) Timer {
+	dt := &deadlineTimer{
+		ctx: ctx,
+		c:   make(chan time.Time, 1),
+	}
+	if fc, ok := c.(*fakeClock); ok {
+		s := &sleeper{
+			fc:    fc,
+			label: "DeadlineTimer",
+			until: deadline,
+			// Called from awaken, so the value is sent before Advance returns.
+			callback: sendDeadline,
+			arg:      dt,
+		}
+		dt.Timer = s
+		fc.addTimer(s)
+	} else {
+		dt.Timer = c.AfterFunc(deadline.Sub(c.Now()), func() { dt.deliver(c.Now()) })
+	}
+	dt.watch()
+	return dt
+}
+
+// sendDeadline is the callback of a deadline timer's sleeper on a fakeClock,
+// whose arg is the timer.
+func sendDeadline(arg interface{}, now time.Time) {
+	arg.(*deadlineTimer).deliver(now)
+}
+
+// deadlineTimer wraps a Timer created by AfterFunc, which sends on c unless
+// ctx is done.
+type deadlineTimer struct {
+	Timer
+	ctx context.Context
+	c   chan time.Time
+
+	l         sync.Mutex    // Guards stopWatch and fired
+	stopWatch chan struct{} // closed to stop the watcher; nil when not watching
+	fired     bool          // set when the Timer fires; cleared by Reset
+}
+
+func (dt *deadlineTimer) C() <-chan time.Time { return dt.c }
+
+// deliver sends now on c, unless ctx is done, once the Timer has fired.
+func (dt *deadlineTimer) deliver(now time.Time) {
+	dt.fire()
+	if dt.ctx.Err() != nil {
+		return
+	}
+	select {
+	case dt.c <- now:
+	default:
+	}
+}
+
+func (dt *deadlineTimer) T() *time.Timer { return nil }
+
+func (dt *deadlineTimer) Reset(d time.Duration) bool {
+	if dt.ctx.Err() != nil {
+		return dt.Stop()
+	}
+	dt.l.Lock()
+	dt.fired = false
+	dt.l.Unlock()
+	active := dt.Timer.Reset(d)
+	dt.watch()
+	return active
+}
+
+func (dt *deadlineTimer) Stop() bool {
+	active := dt.Timer.Stop()
+	dt.unwatch()
+	return active
+}
+
+// watch starts a goroutine which stops the Timer when ctx is done, unless one
+// is already running or the Timer has already fired.
+func (dt *deadlineTimer) watch() {
+	dt.l.Lock()
+	defer dt.l.Unlock()
+	if dt.fired || dt.stopWatch != nil {
+		return
+	}
+	stop := make(chan struct{})
+	dt.stopWatch = stop
+	go func() {
+		select {
+		case <-dt.ctx.Done():
+			dt.Stop()
+		case <-stop:
+		}
+	}()
+}
+
+// unwatch stops the watcher goroutine, if it is running.
+func (dt *deadlineTimer) unwatch() {
+	dt.l.Lock()
+	defer dt.l.Unlock()
+	dt.unwatchLocked()
+}
+
+// fire records that the Timer has fired, so that a watch racing with an
+// already-passed deadline does not start a watcher nothing will stop.
+func (dt *deadlineTimer) fire() {
+	dt.l.Lock()
+	defer dt.l.Unlock()
+	dt.fired = true
+	dt.unwatchLocked()
+}
+
+func (dt *deadlineTimer) unwatchLocked() {
+	if dt.stopWatch != nil {
+		close(dt.stopWatch)
+		dt.stopWatch = nil
+	}
+}
+
+// watching reports whether the watcher goroutine is running.
+func (dt *deadlineTimer) watching() bool {
+	dt.l.Lock()
+	defer dt.l.Unlock()
+	return dt.stopWatch != nil
+}
@@ -0,0 +1,75 @@
+package clockwork
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDeadlineTimerFires(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	deadline := fc.Now().Add(time.Second)
+
+	dt := NewDeadlineTimer(ctx, fc, deadline)
+	fc.BlockUntil(1)
+	fc.Advance(time.Second)
+	// Like other fake timers, it fires before Advance returns.
+	select {
+	case tick := <-dt.C():
+		if !tick.Equal(deadline) {
+			t.Errorf("fired at %v, want %v", tick, deadline)
+		}
+	default:
+		t.Fatalf("deadline timer did not fire by the time Advance returned")
+	}
+	if dt.(*deadlineTimer).watching() {
+		t.Errorf("watcher still running after the timer fired")
+	}
+}
+
+func TestDeadlineTimerCancelled(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	dt := NewDeadlineTimer(ctx, fc, fc.Now().Add(time.Second))
+	fc.BlockUntil(1)
+	cancel()
+	// The watcher stops the timer, releasing its sleeper.
+	withTimeout(t, time.Second, func() { fc.BlockUntil(0) })
+
+	fc.Advance(time.Second)
+	select {
+	case tick := <-dt.C():
+		t.Errorf("cancelled deadline timer fired at %v", tick)
+	case <-time.After(10 * time.Millisecond):
+	}
+	if dt.Reset(time.Second) {
+		t.Errorf("Reset() = true for a cancelled deadline timer")
+	}
+	if n := len(fc.(*fakeClock).sleepers); n != 0 {
+		t.Errorf("Reset after cancellation registered %d sleepers", n)
+	}
+}
+
+func TestDeadlineTimerPastDeadline(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	// ctx is never done, so only the Timer firing can stop the watcher.
+	ctx := context.Background()
+
+	for i := 0; i < 100; i++ {
+		dt := NewDeadlineTimer(ctx, fc, fc.Now().Add(-time.Second))
+		select {
+		case <-dt.C():
+		case <-time.After(time.Second):
+			t.Fatalf("deadline timer with a past deadline did not fire")
+		}
+		if dt.(*deadlineTimer).watching() {
+			t.Fatalf("watcher still running after a past deadline fired")
+		}
+	}
+}
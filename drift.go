@@ -0,0 +1,118 @@
+package clockwork
+
+import (
+	"sync"
+	"time"
+)
+
+// NewAssertingRealClock returns a real Clock which checks when its timers
+// fire. Whenever a timer from After, NewTimer, AfterFunc or their String and
+// Done variants fires, or a Sleep returns, more than maxDrift after its
+// deadline, onDrift is called with the deadline and the time it actually
+// fired, on the timer's goroutine. This surfaces the timing problems of a
+// loaded CI machine, which otherwise show up as flaky tests. Tickers are not
+// checked.
+func NewAssertingRealClock(maxDrift time.Duration, onDrift func(expected, actual time.Time)) Clock {
+	return &driftClock{
+		Clock:    NewRealClock(),
+		maxDrift: maxDrift,
+		onDrift:  onDrift,
+	}
+}
+
+type driftClock struct {
+	Clock
+
+	maxDrift time.Duration
+	onDrift  func(expected, actual time.Time)
+}
+
+// check calls onDrift if actual is more than maxDrift after expected.
+func (dc *driftClock) check(expected, actual time.Time) {
+	if actual.Sub(expected) > dc.maxDrift {
+		dc.onDrift(expected, actual)
+	}
+}
+
+func (dc *driftClock) After(d time.Duration) <-chan time.Time {
+	return dc.NewTimer(d).C()
+}
+
+func (dc *driftClock) Sleep(d time.Duration) {
+	expected := dc.Clock.Now().Add(d)
+	dc.Clock.Sleep(d)
+	dc.check(expected, dc.Clock.Now())
+}
+
+func (dc *driftClock) NewTimer(d time.Duration) Timer {
+	dt := &driftTimer{
+		clock:    dc.Clock,
+		c:        make(chan time.Time, 1),
+		expected: dc.Clock.Now().Add(d),
+	}
+	dt.Timer = dc.Clock.AfterFunc(d, func() {
+		now := dc.Clock.Now()
+		dc.check(dt.deadline(), now)
+		select {
+		case dt.c <- now:
+		default:
+		}
+	})
+	return dt
+}
+
+func (dc *driftClock) AfterFunc(d time.Duration, f func()) Timer {
+	dt := &driftTimer{clock: dc.Clock, expected: dc.Clock.Now().Add(d)}
+	dt.Timer = dc.Clock.AfterFunc(d, func() {
+		dc.check(dt.deadline(), dc.Clock.Now())
+		f()
+	})
+	return dt
+}
+
+func (dc *driftClock) AfterFuncDone(d time.Duration, f func()) (Timer, <-chan struct{}) {
+	return afterFuncDone(dc.AfterFunc, d, f)
+}
+
+func (dc *driftClock) AfterString(s string) (<-chan time.Time, error) {
+	return afterString(dc, s)
+}
+
+func (dc *driftClock) NewTimerString(s string) (Timer, error) {
+	return newTimerString(dc, s)
+}
+
+// Unwrap returns the wrapped real Clock.
+func (dc *driftClock) Unwrap() Clock {
+	return dc.Clock
+}
+
+// driftTimer wraps a Timer created by AfterFunc on clock, recording its
+// deadline for the drift check. For NewTimer it sends on c; for AfterFunc c is
+// nil. T returns the wrapped Timer's underlying *time.Timer, which runs the
+// check rather than sending on a channel.
+type driftTimer struct {
+	Timer
+	clock Clock
+	c     chan time.Time
+
+	l        sync.Mutex // Guards expected
+	expected time.Time
+}
+
+func (dt *driftTimer) C() <-chan time.Time { return dt.c }
+
+func (dt *driftTimer) T() *time.Timer { return dt.Timer.T() }
+
+func (dt *driftTimer) Reset(d time.Duration) bool {
+	dt.l.Lock()
+	dt.expected = dt.clock.Now().Add(d)
+	dt.l.Unlock()
+	return dt.Timer.Reset(d)
+}
+
+func (dt *driftTimer) deadline() time.Time {
+	dt.l.Lock()
+	defer dt.l.Unlock()
+	return dt.expected
+}
@@ -0,0 +1,87 @@
+package clockwork
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAssertingRealClock(t *testing.T) {
+	t.Parallel()
+	var (
+		l      sync.Mutex
+		drifts []time.Duration
+	)
+	onDrift := func(expected, actual time.Time) {
+		l.Lock()
+		defer l.Unlock()
+		drifts = append(drifts, actual.Sub(expected))
+	}
+
+	// A generous window allows for scheduling delays.
+	c := NewAssertingRealClock(time.Hour, onDrift)
+	<-c.After(time.Millisecond)
+	c.Sleep(time.Millisecond)
+	if len(drifts) != 0 {
+		t.Errorf("drift reported within the window: %v", drifts)
+	}
+
+	// Real timers always fire a little after their deadline, so a window of
+	// one nanosecond reports them.
+	c = NewAssertingRealClock(time.Nanosecond, onDrift)
+	done := make(chan struct{})
+	c.AfterFunc(time.Millisecond, func() {
+		<-c.After(time.Millisecond)
+		close(done)
+	})
+	<-done
+
+	l.Lock()
+	defer l.Unlock()
+	if len(drifts) == 0 {
+		t.Fatalf("no drift reported for timers beyond the window")
+	}
+	for _, d := range drifts {
+		if d <= time.Nanosecond {
+			t.Errorf("reported drift %v within the window", d)
+		}
+	}
+}
+
+func TestAssertingRealClockTimerT(t *testing.T) {
+	t.Parallel()
+	c := NewAssertingRealClock(time.Hour, func(expected, actual time.Time) {})
+	timer := c.AfterFunc(time.Hour, func() {})
+	defer timer.Stop()
+	if timer.T() == nil {
+		t.Errorf("T() = nil for a timer from the asserting real clock")
+	}
+}
+
+func TestAssertingRealClockVariants(t *testing.T) {
+	t.Parallel()
+	for name, wait := range map[string]func(c Clock){
+		"AfterString": func(c Clock) {
+			ch, _ := c.AfterString("1ms")
+			<-ch
+		},
+		"NewTimerString": func(c Clock) {
+			timer, _ := c.NewTimerString("1ms")
+			<-timer.C()
+		},
+		"AfterFuncDone": func(c Clock) {
+			_, done := c.AfterFuncDone(time.Millisecond, func() {})
+			<-done
+		},
+	} {
+		var drifts int32
+		c := NewAssertingRealClock(time.Nanosecond, func(expected, actual time.Time) {
+			atomic.AddInt32(&drifts, 1)
+		})
+		wait(c)
+		if atomic.LoadInt32(&drifts) == 0 {
+			t.Errorf("%s: no drift reported beyond a 1ns window", name)
+		}
+	}
+}
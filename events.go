@@ -0,0 +1,79 @@
+package clockwork
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventType identifies the kind of an Event.
+type EventType int
+
+const (
+	// EventAdvance records a call to Advance.
+	EventAdvance EventType = iota
+	// EventSet records a call to Set.
+	EventSet
+	// EventTimerCreated records the creation, or Reset, of a timer.
+	EventTimerCreated
+	// EventTimerFired records a timer firing.
+	EventTimerFired
+	// EventTimerStopped records a pending timer being stopped.
+	EventTimerStopped
+	// EventJump records a call to Jump.
+	EventJump
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventAdvance:
+		return "Advance"
+	case EventSet:
+		return "Set"
+	case EventTimerCreated:
+		return "TimerCreated"
+	case EventTimerFired:
+		return "TimerFired"
+	case EventTimerStopped:
+		return "TimerStopped"
+	case EventJump:
+		return "Jump"
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Event is an entry in a FakeClock's event log. See WithEventLog.
+type Event struct {
+	Type EventType
+	// Time is the clock's time when the event occurred. For Advance, Set and
+	// Jump events it is the time the clock was moved to.
+	Time time.Time
+	// Label describes the timer for timer events: the name of the method
+	// which created it, such as "After" or "AfterFunc". It is empty for
+	// other events.
+	Label string
+}
+
+func (e Event) String() string {
+	if e.Label == "" {
+		return fmt.Sprintf("%v %v", e.Time, e.Type)
+	}
+	return fmt.Sprintf("%v %v %s", e.Time, e.Type, e.Label)
+}
+
+// logEvent appends an event to the log, if enabled.
+func (fc *fakeClock) logEvent(typ EventType, t time.Time, label string) {
+	if !fc.eventLog {
+		return
+	}
+	fc.eventsL.Lock()
+	defer fc.eventsL.Unlock()
+	fc.events = append(fc.events, Event{Type: typ, Time: t, Label: label})
+}
+
+// EventLog returns a copy of the events recorded so far, if the fakeClock was
+// created using WithEventLog.
+func (fc *fakeClock) EventLog() []Event {
+	fc.eventsL.Lock()
+	defer fc.eventsL.Unlock()
+	return append([]Event(nil), fc.events...)
+}
@@ -0,0 +1,44 @@
+package clockwork
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEventLog(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClockAtEpoch(WithEventLog())
+	at := func(d time.Duration) time.Time { return time.Unix(0, 0).UTC().Add(d) }
+
+	fc.After(2 * time.Second)
+	timer := fc.NewTimer(time.Second)
+	fc.Advance(time.Second)
+	timer.Reset(time.Second)
+	timer.Stop()
+	fc.Set(at(5 * time.Second))
+
+	want := []Event{
+		{EventTimerCreated, at(0), "After"},
+		{EventTimerCreated, at(0), "NewTimer"},
+		{EventAdvance, at(time.Second), ""},
+		{EventTimerFired, at(time.Second), "NewTimer"},
+		{EventTimerCreated, at(time.Second), "NewTimer"},
+		{EventTimerStopped, at(time.Second), "NewTimer"},
+		{EventSet, at(5 * time.Second), ""},
+		{EventTimerFired, at(5 * time.Second), "After"},
+	}
+	if got := fc.EventLog(); !reflect.DeepEqual(got, want) {
+		t.Errorf("EventLog() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestEventLogDisabled(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	fc.After(time.Second)
+	fc.Advance(time.Second)
+	if got := fc.EventLog(); len(got) != 0 {
+		t.Errorf("EventLog() without WithEventLog = %v, want empty", got)
+	}
+}
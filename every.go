@@ -0,0 +1,43 @@
+package clockwork
+
+import (
+	"sync"
+	"time"
+)
+
+// Every calls f every d on c, in its own goroutine, until the returned stop
+// func is called. It is built on c.NewTicker, so on a FakeClock each period
+// reached by Advance or Set triggers a call, and like a ticker's, calls are
+// dropped if f is still running from earlier periods.
+//
+// Once stop returns, the loop ends and f is not scheduled again, but stop does
+// not wait for it: a call which had already begun may still be running, and
+// one which was just about to begin may still start. Calling stop stops the
+// ticker straight away, and it is safe to call it more than once, including
+// from f itself. Every panics if d is not positive.
+func Every(c Clock, d time.Duration, f func()) (stop func()) {
+	t := c.NewTicker(d)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.Chan():
+			}
+			select {
+			case <-done:
+				return
+			default:
+				f()
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.Stop()
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package clockwork
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvery(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	calls := make(chan time.Time)
+	stop := Every(fc, time.Second, func() { calls <- fc.Now() })
+
+	start := fc.Now()
+	for i := 1; i <= 3; i++ {
+		fc.Advance(time.Second)
+		select {
+		case now := <-calls:
+			if want := start.Add(time.Duration(i) * time.Second); !now.Equal(want) {
+				t.Errorf("call %d at %v, want %v", i, now, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("f not called after period %d", i)
+		}
+	}
+
+	stop()
+	stop()
+	if n := fc.ActiveTickers(); n != 0 {
+		t.Errorf("ActiveTickers() = %d after stop, want 0", n)
+	}
+	fc.Advance(3 * time.Second)
+	select {
+	case <-calls:
+		t.Errorf("f called after stop")
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestEveryRealClock(t *testing.T) {
+	t.Parallel()
+	calls := make(chan struct{}, 1)
+	stop := Every(NewRealClock(), time.Millisecond, func() {
+		select {
+		case calls <- struct{}{}:
+		default:
+		}
+	})
+	defer stop()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-calls:
+		case <-time.After(time.Second):
+			t.Fatalf("f not called")
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package clockwork
+
+import (
+	"sync"
+	"time"
+)
+
+// FreezableClock wraps a Clock so that Now can be frozen at a single instant,
+// for example to give every read within a request a consistent current time.
+// Freezing only affects the times the clock reports: timers, tickers and
+// sleeps keep running on the wrapped Clock.
+type FreezableClock struct {
+	Clock
+
+	l      sync.RWMutex
+	frozen *time.Time // nil when not frozen
+}
+
+// NewFreezableClock returns a FreezableClock wrapping base, initially not
+// frozen.
+func NewFreezableClock(base Clock) *FreezableClock {
+	return &FreezableClock{Clock: base}
+}
+
+// Freeze makes Now return the wrapped clock's current time until Unfreeze is
+// called. Freezing an already frozen clock captures the time afresh.
+func (c *FreezableClock) Freeze() {
+	now := c.Clock.Now()
+	c.l.Lock()
+	defer c.l.Unlock()
+	c.frozen = &now
+}
+
+// Unfreeze makes Now track the wrapped clock again.
+func (c *FreezableClock) Unfreeze() {
+	c.l.Lock()
+	defer c.l.Unlock()
+	c.frozen = nil
+}
+
+// Now returns the time captured by Freeze if the clock is frozen, and the
+// wrapped clock's current time otherwise.
+func (c *FreezableClock) Now() time.Time {
+	c.l.RLock()
+	defer c.l.RUnlock()
+	if c.frozen != nil {
+		return *c.frozen
+	}
+	return c.Clock.Now()
+}
+
+// NowUnixNano returns Now in Unix nanoseconds.
+func (c *FreezableClock) NowUnixNano() int64 {
+	return c.Now().UnixNano()
+}
+
+// NowUnixMilli returns Now in Unix milliseconds.
+func (c *FreezableClock) NowUnixMilli() int64 {
+	return unixMilli(c.Now())
+}
+
+// NowUnix returns Now in Unix seconds.
+func (c *FreezableClock) NowUnix() int64 {
+	return c.Now().Unix()
+}
+
+// Since returns the time elapsed since t, measured from Now.
+func (c *FreezableClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Until returns the time until t, measured from Now.
+func (c *FreezableClock) Until(t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+// NextMidnight returns the next midnight after Now.
+func (c *FreezableClock) NextMidnight() time.Time {
+	return nextMidnight(c.Now())
+}
+
+// NowTruncated returns Now truncated to a multiple of d.
+func (c *FreezableClock) NowTruncated(d time.Duration) time.Time {
+	return c.Now().Truncate(d)
+}
+
+// NowRounded returns Now rounded to the nearest multiple of d.
+func (c *FreezableClock) NowRounded(d time.Duration) time.Time {
+	return c.Now().Round(d)
+}
+
+// Unwrap returns the wrapped Clock.
+func (c *FreezableClock) Unwrap() Clock {
+	return c.Clock
+}
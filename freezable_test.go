@@ -0,0 +1,36 @@
+package clockwork
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFreezableClock(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	c := NewFreezableClock(fc)
+	start := fc.Now()
+
+	c.Freeze()
+	timer := c.NewTimer(time.Second)
+	fc.Advance(time.Second)
+	if now := c.Now(); !now.Equal(start) {
+		t.Errorf("frozen Now() = %v, want %v", now, start)
+	}
+	if since := c.Since(start); since != 0 {
+		t.Errorf("frozen Since() = %v, want 0", since)
+	}
+	if until := c.Until(start.Add(time.Second)); until != time.Second {
+		t.Errorf("frozen Until() = %v, want %v", until, time.Second)
+	}
+	select {
+	case <-timer.C():
+	default:
+		t.Errorf("timer did not fire while the clock was frozen")
+	}
+
+	c.Unfreeze()
+	if now, want := c.Now(), start.Add(time.Second); !now.Equal(want) {
+		t.Errorf("unfrozen Now() = %v, want %v", now, want)
+	}
+}
@@ -0,0 +1,106 @@
+package clockwork
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrFrozenClock is the value a Clock created by NewStrictFrozenClock panics
+// with when asked to wait.
+var ErrFrozenClock = errors.New("waiting on a frozen clock, which never advances")
+
+// NewFrozenClock returns a Clock which is always at t, for code which only
+// reads the time. Now, Since, Until and the other methods which read the time
+// are based on t. As time never moves, everything which waits for it waits
+// forever: channels from After and timers never fire, tickers never tick, and
+// Sleep blocks forever, unless asked to wait for no time at all, as for
+// After(0), in which case they return or fire straight away. Use
+// NewStrictFrozenClock to catch such waits instead.
+func NewFrozenClock(t time.Time) Clock {
+	return ReadOnly(NewFakeClockAt(t))
+}
+
+// NewStrictFrozenClock is like NewFrozenClock, but the returned Clock panics
+// with ErrFrozenClock whenever it is asked to wait: by Sleep or SleepUntil, or
+// by creating a timer or ticker.
+func NewStrictFrozenClock(t time.Time) Clock {
+	return strictFrozenClock{NewFrozenClock(t)}
+}
+
+// strictFrozenClock panics in every method which waits for time to pass.
+type strictFrozenClock struct {
+	Clock
+}
+
+func (strictFrozenClock) After(time.Duration) <-chan time.Time {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) Sleep(time.Duration) {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) SleepUntil(time.Time) {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) NewTicker(time.Duration) Ticker {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) NewTickerImmediate(time.Duration) Ticker {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) TryNewTicker(time.Duration) (Ticker, error) {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) NewBurstTicker(time.Duration, int) Ticker {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) NewBufferedTicker(time.Duration, int) Ticker {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) NewAlignedTicker(time.Duration) Ticker {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) NewSkewedTicker(time.Duration, func(int) time.Duration) Ticker {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) NewTimer(time.Duration) Timer {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) NewTimerAt(time.Time) Timer {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) AfterAt(time.Time) <-chan time.Time {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) AfterString(string) (<-chan time.Time, error) {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) NewTimerString(string) (Timer, error) {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) AfterFunc(time.Duration, func()) Timer {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) AfterFuncDone(time.Duration, func()) (Timer, <-chan struct{}) {
+	panic(ErrFrozenClock)
+}
+
+func (strictFrozenClock) DeadlineContext(context.Context, time.Time) (context.Context, context.CancelFunc) {
+	panic(ErrFrozenClock)
+}
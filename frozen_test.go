@@ -0,0 +1,54 @@
+package clockwork
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFrozenClock(t *testing.T) {
+	t.Parallel()
+	at := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewFrozenClock(at)
+	if _, ok := c.(FakeClock); ok || IsFake(c) {
+		t.Errorf("frozen clock exposes its FakeClock")
+	}
+
+	ch := c.After(time.Nanosecond)
+	for i := 0; i < 3; i++ {
+		if now := c.Now(); !now.Equal(at) {
+			t.Errorf("Now() = %v, want %v", now, at)
+		}
+		if since := c.Since(at.Add(-time.Hour)); since != time.Hour {
+			t.Errorf("Since() = %v, want %v", since, time.Hour)
+		}
+		if until := c.Until(at.Add(time.Hour)); until != time.Hour {
+			t.Errorf("Until() = %v, want %v", until, time.Hour)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case v := <-ch:
+		t.Errorf("After fired at %v on a frozen clock", v)
+	default:
+	}
+}
+
+func TestStrictFrozenClock(t *testing.T) {
+	t.Parallel()
+	at := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewStrictFrozenClock(at)
+	if now := c.Now(); !now.Equal(at) {
+		t.Errorf("Now() = %v, want %v", now, at)
+	}
+	defer func() {
+		if err := recover(); err != ErrFrozenClock {
+			t.Errorf("After panicked with %v, want %v", err, ErrFrozenClock)
+		}
+	}()
+	mustPanic(t, "Sleep", func() { c.Sleep(time.Second) })
+	mustPanic(t, "NewTicker", func() { c.NewTicker(time.Second) })
+	mustPanic(t, "AfterFunc", func() { c.AfterFunc(time.Second, func() {}) })
+	mustPanic(t, "DeadlineContext", func() { c.DeadlineContext(context.Background(), at) })
+	c.After(time.Second)
+}
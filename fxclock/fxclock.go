@@ -0,0 +1,58 @@
+// Package fxclock adapts clockwork clocks to the small clock interface which
+// lifecycle code in dependency injection frameworks, such as applications
+// built on go.uber.org/fx, typically accepts for its start and stop timeouts:
+// Now, After and NewTimer, with a timer whose channel is returned by Chan.
+// fx itself keeps its clock interface internal, so Clock and Timer here
+// define that shape for application code to depend on, and a FakeClock can
+// then drive the timeouts in tests.
+package fxclock
+
+import (
+	"time"
+
+	"github.com/jangala-dev/clockwork"
+)
+
+// Clock is the method set lifecycle code needs from a clock.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the method set lifecycle code needs from a timer. Chan returns the
+// channel on which the Timer delivers its time.
+type Timer interface {
+	Chan() <-chan time.Time
+	Reset(d time.Duration) bool
+	Stop() bool
+}
+
+// New returns a Clock backed by c.
+func New(c clockwork.Clock) Clock {
+	return &clock{c: c}
+}
+
+type clock struct {
+	c clockwork.Clock
+}
+
+func (c *clock) Now() time.Time { return c.c.Now() }
+
+func (c *clock) After(d time.Duration) <-chan time.Time { return c.c.After(d) }
+
+func (c *clock) NewTimer(d time.Duration) Timer { return FromTimer(c.c.NewTimer(d)) }
+
+// FromTimer adapts a clockwork Timer, whose channel is returned by C, to a
+// Timer, whose channel is returned by Chan.
+func FromTimer(t clockwork.Timer) Timer {
+	return &timer{t}
+}
+
+type timer struct {
+	t clockwork.Timer
+}
+
+func (t *timer) Chan() <-chan time.Time     { return t.t.C() }
+func (t *timer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+func (t *timer) Stop() bool                 { return t.t.Stop() }
@@ -0,0 +1,55 @@
+package fxclock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jangala-dev/clockwork"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	fc := clockwork.NewFakeClock()
+	c := New(fc)
+	start := fc.Now()
+
+	if got := c.Now(); !got.Equal(start) {
+		t.Errorf("Now() = %v, want %v", got, start)
+	}
+	after := c.After(time.Second)
+	timer := c.NewTimer(2 * time.Second)
+	stopped := c.NewTimer(time.Second)
+	if !stopped.Stop() {
+		t.Errorf("Stop() = false for a pending timer")
+	}
+
+	fc.Advance(time.Second)
+	select {
+	case got := <-after:
+		if want := start.Add(time.Second); !got.Equal(want) {
+			t.Errorf("After sent %v, want %v", got, want)
+		}
+	default:
+		t.Errorf("After didn't fire")
+	}
+	select {
+	case <-stopped.Chan():
+		t.Errorf("stopped timer fired")
+	case <-timer.Chan():
+		t.Errorf("timer fired early")
+	default:
+	}
+
+	if !timer.Reset(time.Second) {
+		t.Errorf("Reset() = false for a pending timer")
+	}
+	fc.Advance(time.Second)
+	select {
+	case got := <-timer.Chan():
+		if want := start.Add(2 * time.Second); !got.Equal(want) {
+			t.Errorf("timer sent %v, want %v", got, want)
+		}
+	default:
+		t.Errorf("reset timer didn't fire")
+	}
+}
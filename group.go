@@ -0,0 +1,46 @@
+package clockwork
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ClockGroup coordinates a set of independent FakeClocks, for example one per
+// node in a simulated distributed system, so that they can be advanced in
+// lockstep.
+type ClockGroup struct {
+	clocks []FakeClock
+}
+
+// NewClockGroup returns a ClockGroup coordinating the given clocks.
+func NewClockGroup(clocks ...FakeClock) *ClockGroup {
+	return &ClockGroup{clocks: clocks}
+}
+
+// AdvanceAll advances every clock in the group by d. Within each clock,
+// sleepers are notified in the usual order; the order in which clocks are
+// advanced relative to each other is unspecified.
+func (g *ClockGroup) AdvanceAll(d time.Duration) {
+	for _, fc := range g.clocks {
+		fc.Advance(d)
+	}
+}
+
+// BlockUntilAll blocks until every clock in the group has the corresponding
+// number of sleepers in counts. It panics if counts does not have one entry
+// per clock.
+func (g *ClockGroup) BlockUntilAll(counts []int) {
+	if len(counts) != len(g.clocks) {
+		panic(fmt.Errorf("got %d counts for a group of %d clocks", len(counts), len(g.clocks)))
+	}
+	var wg sync.WaitGroup
+	for i, fc := range g.clocks {
+		wg.Add(1)
+		go func(fc FakeClock, n int) {
+			defer wg.Done()
+			fc.BlockUntil(n)
+		}(fc, counts[i])
+	}
+	wg.Wait()
+}
@@ -0,0 +1,44 @@
+package clockwork
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClockGroup(t *testing.T) {
+	t.Parallel()
+	a, b := NewFakeClock(), NewFakeClockAtEpoch()
+	g := NewClockGroup(a, b)
+	aStart, bStart := a.Now(), b.Now()
+
+	// One node sleeps once, the other twice in parallel.
+	var wg sync.WaitGroup
+	sleep := func(c Clock, d time.Duration) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Sleep(d)
+		}()
+	}
+	sleep(a, time.Second)
+	sleep(b, time.Second)
+	sleep(b, 2*time.Second)
+
+	withTimeout(t, time.Second, func() {
+		g.BlockUntilAll([]int{1, 2})
+		g.AdvanceAll(time.Second)
+		g.BlockUntilAll([]int{0, 1})
+		g.AdvanceAll(time.Second)
+		wg.Wait()
+	})
+
+	if got := a.Since(aStart); got != 2*time.Second {
+		t.Errorf("first clock advanced by %v, want 2s", got)
+	}
+	if got := b.Since(bStart); got != 2*time.Second {
+		t.Errorf("second clock advanced by %v, want 2s", got)
+	}
+
+	mustPanic(t, "BlockUntilAll with wrong number of counts", func() { g.BlockUntilAll([]int{0}) })
+}
@@ -0,0 +1,72 @@
+package clockwork
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// NewJitterClock returns a Clock which perturbs the durations passed to After,
+// Sleep, NewTimer, AfterFunc and their variants by a random amount of up to frac of their
+// length in either direction, before delegating to base. Randomness is drawn
+// from rng, so seeding it makes the jitter reproducible. Tickers are not
+// jittered.
+func NewJitterClock(base Clock, rng *rand.Rand, frac float64) Clock {
+	return &jitterClock{
+		Clock: base,
+		rng:   rng,
+		frac:  frac,
+	}
+}
+
+type jitterClock struct {
+	Clock
+
+	l    sync.Mutex // Guards rng, which is not safe for concurrent use
+	rng  *rand.Rand
+	frac float64
+}
+
+// jitter returns d perturbed by up to jc.frac of its length.
+func (jc *jitterClock) jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	jc.l.Lock()
+	r := jc.rng.Float64()
+	jc.l.Unlock()
+	return d + time.Duration(float64(d)*jc.frac*(2*r-1))
+}
+
+func (jc *jitterClock) After(d time.Duration) <-chan time.Time {
+	return jc.Clock.After(jc.jitter(d))
+}
+
+func (jc *jitterClock) Sleep(d time.Duration) {
+	jc.Clock.Sleep(jc.jitter(d))
+}
+
+func (jc *jitterClock) NewTimer(d time.Duration) Timer {
+	return jc.Clock.NewTimer(jc.jitter(d))
+}
+
+func (jc *jitterClock) AfterFunc(d time.Duration, f func()) Timer {
+	return jc.Clock.AfterFunc(jc.jitter(d), f)
+}
+
+func (jc *jitterClock) AfterFuncDone(d time.Duration, f func()) (Timer, <-chan struct{}) {
+	return afterFuncDone(jc.AfterFunc, d, f)
+}
+
+func (jc *jitterClock) AfterString(s string) (<-chan time.Time, error) {
+	return afterString(jc, s)
+}
+
+func (jc *jitterClock) NewTimerString(s string) (Timer, error) {
+	return newTimerString(jc, s)
+}
+
+// Unwrap returns the wrapped Clock.
+func (jc *jitterClock) Unwrap() Clock {
+	return jc.Clock
+}
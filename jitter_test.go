@@ -0,0 +1,55 @@
+package clockwork
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestJitterClockBounds(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	jc := NewJitterClock(fc, rand.New(rand.NewSource(1)), 0.5)
+
+	for i := 0; i < 100; i++ {
+		timer := jc.NewTimer(time.Second)
+		fc.Advance(500*time.Millisecond - 1)
+		if !IsActive(timer) {
+			t.Fatalf("timer fired before the lower jitter bound")
+		}
+		fc.Advance(time.Second + 1)
+		if IsActive(timer) {
+			t.Fatalf("timer did not fire by the upper jitter bound")
+		}
+	}
+}
+
+func TestJitterClockAfterFuncDone(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	jc := NewJitterClock(fc, rand.New(rand.NewSource(1)), 0.5)
+
+	timer, _ := jc.AfterFuncDone(time.Second, func() {})
+	defer timer.Stop()
+	if deadline, _ := timer.Deadline(); deadline.Equal(fc.Now().Add(time.Second)) {
+		t.Errorf("AfterFuncDone deadline %v has no jitter", deadline)
+	}
+}
+
+func TestJitterClockReproducible(t *testing.T) {
+	t.Parallel()
+	deadlines := func() []time.Time {
+		jc := NewJitterClock(NewFakeClock(), rand.New(rand.NewSource(42)), 0.2)
+		var got []time.Time
+		for i := 0; i < 10; i++ {
+			got = append(got, jc.NewTimer(time.Minute).(*sleeper).Until())
+		}
+		return got
+	}
+	a, b := deadlines(), deadlines()
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			t.Fatalf("deadline %d differs between identically seeded clocks: %v != %v", i, a[i], b[i])
+		}
+	}
+}
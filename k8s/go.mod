@@ -0,0 +1,11 @@
+module github.com/jangala-dev/clockwork/k8s
+
+go 1.13
+
+require (
+	github.com/jangala-dev/clockwork v0.0.0-20261016155423-1919f13d58d0
+	k8s.io/utils v0.0.0-20220210201930-3a6ce19ff2f9
+)
+
+// Development in this repository uses the root module as checked out.
+replace github.com/jangala-dev/clockwork => ../
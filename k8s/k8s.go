@@ -0,0 +1,62 @@
+// Package k8s adapts clockwork clocks to the Clock interfaces of
+// k8s.io/utils/clock, so that a FakeClock can be injected into Kubernetes
+// client code under test. It lives in its own module so that clockwork itself
+// doesn't depend on k8s.io/utils.
+package k8s
+
+import (
+	"time"
+
+	"github.com/jangala-dev/clockwork"
+	k8sclock "k8s.io/utils/clock"
+)
+
+// AsK8sClock returns a k8sclock.Clock backed by c. The returned value also
+// implements k8sclock.WithTickerAndDelayedExecution, for code which needs
+// NewTicker or AfterFunc.
+func AsK8sClock(c clockwork.Clock) k8sclock.Clock {
+	return &k8sClock{c: c}
+}
+
+var _ k8sclock.WithTickerAndDelayedExecution = &k8sClock{}
+
+type k8sClock struct {
+	c clockwork.Clock
+}
+
+func (kc *k8sClock) Now() time.Time { return kc.c.Now() }
+
+func (kc *k8sClock) Since(t time.Time) time.Duration { return kc.c.Since(t) }
+
+func (kc *k8sClock) After(d time.Duration) <-chan time.Time { return kc.c.After(d) }
+
+// NewTimer returns the clockwork Timer directly: it already has the method set
+// of a k8sclock.Timer.
+func (kc *k8sClock) NewTimer(d time.Duration) k8sclock.Timer { return kc.c.NewTimer(d) }
+
+func (kc *k8sClock) Sleep(d time.Duration) { kc.c.Sleep(d) }
+
+// Tick is like time.Tick: the underlying Ticker can never be stopped.
+func (kc *k8sClock) Tick(d time.Duration) <-chan time.Time {
+	if d <= 0 {
+		return nil
+	}
+	return kc.c.NewTicker(d).Chan()
+}
+
+func (kc *k8sClock) NewTicker(d time.Duration) k8sclock.Ticker {
+	return &ticker{kc.c.NewTicker(d)}
+}
+
+func (kc *k8sClock) AfterFunc(d time.Duration, f func()) k8sclock.Timer {
+	return kc.c.AfterFunc(d, f)
+}
+
+// ticker adapts a clockwork Ticker, whose channel is returned by Chan, to a
+// k8sclock.Ticker, whose channel is returned by C.
+type ticker struct {
+	t clockwork.Ticker
+}
+
+func (t *ticker) C() <-chan time.Time { return t.t.Chan() }
+func (t *ticker) Stop()               { t.t.Stop() }
@@ -0,0 +1,63 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jangala-dev/clockwork"
+	k8sclock "k8s.io/utils/clock"
+)
+
+func TestAsK8sClock(t *testing.T) {
+	t.Parallel()
+	fc := clockwork.NewFakeClock()
+	c := AsK8sClock(fc)
+	start := fc.Now()
+
+	if got := c.Now(); !got.Equal(start) {
+		t.Errorf("Now() = %v, want %v", got, start)
+	}
+
+	timer := c.NewTimer(time.Second)
+	stopped := c.NewTimer(time.Second)
+	if !stopped.Stop() {
+		t.Errorf("Stop() = false for a pending timer")
+	}
+	tc, ok := c.(k8sclock.WithTickerAndDelayedExecution)
+	if !ok {
+		t.Fatalf("%T does not implement WithTickerAndDelayedExecution", c)
+	}
+	ticker := tc.NewTicker(time.Second)
+	defer ticker.Stop()
+	ran := make(chan struct{})
+	tc.AfterFunc(time.Second, func() { close(ran) })
+	fc.BlockUntil(3)
+
+	fc.Advance(time.Second)
+	select {
+	case <-timer.C():
+	case <-time.After(time.Second):
+		t.Errorf("timer did not fire")
+	}
+	select {
+	case tick := <-ticker.C():
+		if want := start.Add(time.Second); !tick.Equal(want) {
+			t.Errorf("tick at %v, want %v", tick, want)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("ticker did not tick")
+	}
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Errorf("AfterFunc did not run")
+	}
+	select {
+	case <-stopped.C():
+		t.Errorf("stopped timer fired")
+	default:
+	}
+	if got := c.Since(start); got != time.Second {
+		t.Errorf("Since() = %v, want 1s", got)
+	}
+}
@@ -0,0 +1,82 @@
+package clockwork
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNonMonotonic is the value a Clock created by NewMonotonicGuardClock
+// panics with when its time goes backward.
+var ErrNonMonotonic = errors.New("clock read earlier than a previous reading")
+
+// NewMonotonicGuardClock returns a Clock which behaves like base, but panics
+// with ErrNonMonotonic if Now, or another method which reads the time such as
+// Since or Until, sees a time earlier than one it saw before. A real clock
+// never goes backward, but a FakeClock can be Set to an earlier time, so
+// wrapping one catches code which assumes the time only moves forward.
+// Reading the same time again is allowed.
+func NewMonotonicGuardClock(base Clock) Clock {
+	return &monotonicClock{Clock: base}
+}
+
+type monotonicClock struct {
+	Clock
+
+	l    sync.Mutex // Guards last
+	last time.Time  // the latest time read; zero before the first
+}
+
+// now reads the time from the wrapped clock, panicking if it is earlier than
+// the latest it has read.
+func (mc *monotonicClock) now() time.Time {
+	mc.l.Lock()
+	defer mc.l.Unlock()
+	now := mc.Clock.Now()
+	if now.Before(mc.last) {
+		panic(ErrNonMonotonic)
+	}
+	mc.last = now
+	return now
+}
+
+func (mc *monotonicClock) Now() time.Time {
+	return mc.now()
+}
+
+func (mc *monotonicClock) NowUnixNano() int64 {
+	return mc.now().UnixNano()
+}
+
+func (mc *monotonicClock) NowUnixMilli() int64 {
+	return unixMilli(mc.now())
+}
+
+func (mc *monotonicClock) NowUnix() int64 {
+	return mc.now().Unix()
+}
+
+func (mc *monotonicClock) Since(t time.Time) time.Duration {
+	return mc.now().Sub(t)
+}
+
+func (mc *monotonicClock) Until(t time.Time) time.Duration {
+	return t.Sub(mc.now())
+}
+
+func (mc *monotonicClock) NextMidnight() time.Time {
+	return nextMidnight(mc.now())
+}
+
+func (mc *monotonicClock) NowTruncated(d time.Duration) time.Time {
+	return mc.now().Truncate(d)
+}
+
+func (mc *monotonicClock) NowRounded(d time.Duration) time.Time {
+	return mc.now().Round(d)
+}
+
+// Unwrap returns the wrapped Clock.
+func (mc *monotonicClock) Unwrap() Clock {
+	return mc.Clock
+}
@@ -0,0 +1,43 @@
+package clockwork
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonotonicGuardClock(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	c := NewMonotonicGuardClock(fc)
+	start := c.Now()
+
+	fc.Advance(time.Second)
+	c.Now()
+	c.Now()
+	fc.Set(start.Add(2 * time.Second))
+	if got := c.Since(start); got != 2*time.Second {
+		t.Errorf("Since(start) = %v, want %v", got, 2*time.Second)
+	}
+	if base := Base(c); base != fc {
+		t.Errorf("Base() = %v, want the FakeClock", base)
+	}
+
+	fc.Set(start.Add(time.Second))
+	defer func() {
+		if err := recover(); err != ErrNonMonotonic {
+			t.Errorf("Now panicked with %v after a backward Set, want %v", err, ErrNonMonotonic)
+		}
+	}()
+	c.Now()
+}
+
+func TestMonotonicGuardClockReads(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	c := NewMonotonicGuardClock(fc)
+	c.Now()
+	fc.Set(fc.Now().Add(-time.Nanosecond))
+	mustPanic(t, "Since", func() { c.Since(time.Time{}) })
+	mustPanic(t, "Until", func() { c.Until(time.Time{}) })
+	mustPanic(t, "NowUnixNano", func() { c.NowUnixNano() })
+}
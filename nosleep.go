@@ -0,0 +1,54 @@
+package clockwork
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewNoSleepRealClock returns a real Clock which panics if Sleep, SleepUntil or
+// After is asked to wait longer than limit. It is a safety net for test
+// suites, catching code which was accidentally given a real clock instead of a
+// FakeClock before it makes the tests slow. Waits of up to limit are allowed,
+// so code which sleeps briefly in real time can still run.
+func NewNoSleepRealClock(limit time.Duration) Clock {
+	return &noSleepClock{
+		Clock: NewRealClock(),
+		limit: limit,
+	}
+}
+
+type noSleepClock struct {
+	Clock
+
+	limit time.Duration
+}
+
+// check panics if d is longer than nc.limit.
+func (nc *noSleepClock) check(d time.Duration) {
+	if d > nc.limit {
+		panic(fmt.Errorf("real wait of %v is longer than the %v allowed", d, nc.limit))
+	}
+}
+
+func (nc *noSleepClock) Sleep(d time.Duration) {
+	nc.check(d)
+	nc.Clock.Sleep(d)
+}
+
+func (nc *noSleepClock) SleepUntil(t time.Time) {
+	nc.Sleep(nc.Clock.Until(t))
+}
+
+func (nc *noSleepClock) After(d time.Duration) <-chan time.Time {
+	nc.check(d)
+	return nc.Clock.After(d)
+}
+
+func (nc *noSleepClock) AfterString(s string) (<-chan time.Time, error) {
+	return afterString(nc, s)
+}
+
+// Unwrap returns the wrapped real Clock.
+func (nc *noSleepClock) Unwrap() Clock {
+	return nc.Clock
+}
@@ -0,0 +1,21 @@
+package clockwork
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNoSleepRealClock(t *testing.T) {
+	t.Parallel()
+	c := NewNoSleepRealClock(10 * time.Millisecond)
+	c.Sleep(time.Millisecond)
+	<-c.After(time.Millisecond)
+	if _, err := c.AfterString("1ms"); err != nil {
+		t.Errorf("AfterString() returned %v", err)
+	}
+
+	mustPanic(t, "Sleep", func() { c.Sleep(time.Hour) })
+	mustPanic(t, "SleepUntil", func() { c.SleepUntil(c.Now().Add(time.Hour)) })
+	mustPanic(t, "After", func() { c.After(time.Hour) })
+	mustPanic(t, "AfterString", func() { c.AfterString("1h") })
+}
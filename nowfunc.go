@@ -0,0 +1,65 @@
+package clockwork
+
+import "time"
+
+// NowFunc returns c.Now as a func, for libraries which take the current time
+// as a `now func() time.Time` rather than a Clock.
+func NowFunc(c Clock) func() time.Time {
+	return c.Now
+}
+
+// FromNowFunc returns a Clock which reads the current time from now. Only the
+// methods which read the time, such as Now, Since and Until, use now: timers,
+// tickers and sleeps run in real time, as they would for NewRealClock.
+func FromNowFunc(now func() time.Time) Clock {
+	return &funcClock{
+		Clock: NewRealClock(),
+		now:   now,
+	}
+}
+
+type funcClock struct {
+	Clock // a real clock, for everything but reading the time
+
+	now func() time.Time
+}
+
+func (fc *funcClock) Now() time.Time {
+	return fc.now()
+}
+
+func (fc *funcClock) NowUnixNano() int64 {
+	return fc.now().UnixNano()
+}
+
+func (fc *funcClock) NowUnixMilli() int64 {
+	return unixMilli(fc.now())
+}
+
+func (fc *funcClock) NowUnix() int64 {
+	return fc.now().Unix()
+}
+
+func (fc *funcClock) Since(t time.Time) time.Duration {
+	return fc.now().Sub(t)
+}
+
+func (fc *funcClock) Until(t time.Time) time.Duration {
+	return t.Sub(fc.now())
+}
+
+func (fc *funcClock) NextMidnight() time.Time {
+	return nextMidnight(fc.now())
+}
+
+func (fc *funcClock) NowTruncated(d time.Duration) time.Time {
+	return fc.now().Truncate(d)
+}
+
+func (fc *funcClock) NowRounded(d time.Duration) time.Time {
+	return fc.now().Round(d)
+}
+
+func (fc *funcClock) Date(year int, month time.Month, day, hour, min, sec, nsec int) time.Time {
+	return time.Date(year, month, day, hour, min, sec, nsec, fc.now().Location())
+}
@@ -0,0 +1,35 @@
+package clockwork
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNowFunc(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	now := NowFunc(fc)
+	start := now()
+	fc.Advance(time.Hour)
+	if got, want := now(), start.Add(time.Hour); !got.Equal(want) {
+		t.Errorf("NowFunc after Advance = %v, want %v", got, want)
+	}
+
+	c := FromNowFunc(now)
+	if got := c.Now(); !got.Equal(fc.Now()) {
+		t.Errorf("FromNowFunc Now() = %v, want %v", got, fc.Now())
+	}
+	if got := c.Since(start); got != time.Hour {
+		t.Errorf("FromNowFunc Since() = %v, want %v", got, time.Hour)
+	}
+	if got := c.Until(start.Add(3 * time.Hour)); got != 2*time.Hour {
+		t.Errorf("FromNowFunc Until() = %v, want %v", got, 2*time.Hour)
+	}
+
+	// Timers run in real time.
+	select {
+	case <-c.After(time.Millisecond):
+	case <-time.After(time.Second):
+		t.Errorf("FromNowFunc After() did not fire in real time")
+	}
+}
@@ -0,0 +1,187 @@
+package clockwork
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// Option configures a FakeClock when it is created.
+type Option func(*fakeClock)
+
+// WithStart makes the FakeClock start at t, rather than at the arbitrary time
+// NewFakeClock uses.
+func WithStart(t time.Time) Option {
+	return func(fc *fakeClock) {
+		fc.setTime(t)
+	}
+}
+
+// WithLocation makes the FakeClock start in loc, so that Now and the methods
+// derived from it report times in loc. It changes only the location of the
+// starting time, not the instant, and applies whether it is given before or
+// after WithStart.
+func WithLocation(loc *time.Location) Option {
+	return func(fc *fakeClock) {
+		fc.location = loc
+	}
+}
+
+// ErrAdvanceLimit is wrapped by the value a FakeClock created WithMaxAdvance
+// panics with when it is moved too far at once.
+var ErrAdvanceLimit = errors.New("clock moved beyond the WithMaxAdvance limit")
+
+// WithMaxAdvance makes the FakeClock panic, with an error wrapping
+// ErrAdvanceLimit, if a single call to Advance or Set would move it by more
+// than d in either direction. It is intended as an assertion guard against
+// code that computes runaway durations.
+func WithMaxAdvance(d time.Duration) Option {
+	return func(fc *fakeClock) {
+		fc.maxAdvance = d
+	}
+}
+
+// WithStrictTickers makes the FakeClock count ticks which its tickers discard
+// because the previous tick had not yet been read, i.e. because the consumer
+// fell behind. The count is reported by TickerOverruns.
+func WithStrictTickers() Option {
+	return func(fc *fakeClock) {
+		fc.strictTickers = true
+	}
+}
+
+// WithGo123Timers gives the FakeClock's timers and tickers the channel
+// semantics introduced in Go 1.23, where timer channels behave as if they were
+// unbuffered: once Stop or Reset returns, no value sent before the call will
+// be received. A timer which has fired but whose value has not been received
+// is considered active, so Stop and Reset return true for it.
+//
+// Without this option timers behave as in earlier Go releases, where a fired
+// value stays buffered in the channel until it is received.
+func WithGo123Timers() Option {
+	return func(fc *fakeClock) {
+		fc.go123Timers = true
+	}
+}
+
+// WithEventLog makes the FakeClock record every Advance, Set, and timer
+// creation, firing and stop, for retrieval with EventLog.
+func WithEventLog() Option {
+	return func(fc *fakeClock) {
+		fc.eventLog = true
+	}
+}
+
+// WithDeferredCallbacks makes the FakeClock queue the functions scheduled with
+// AfterFunc when they become due, rather than running each on a new goroutine.
+// Queued functions run when RunPendingCallbacks is called, on the calling
+// goroutine, which gives tests full control over when and where they run. This
+// applies to AdvanceAndWait too, which then has nothing to wait for.
+func WithDeferredCallbacks() Option {
+	return func(fc *fakeClock) {
+		fc.deferredCallbacks = true
+	}
+}
+
+// WithResolution makes the FakeClock's Now, and the methods derived from it
+// such as Since, report its time truncated to a multiple of d, to reproduce
+// bugs which only show on platforms with a coarse system clock. The clock
+// keeps full precision internally: Advance moves it exactly, and timers fire
+// and report exact times. Helpers which compute durations from Now see the
+// truncated time.
+func WithResolution(d time.Duration) Option {
+	return func(fc *fakeClock) {
+		fc.resolution = d
+	}
+}
+
+// WithYields sets the number of times AdvanceAndYield yields the processor
+// after advancing the FakeClock. More yields give functions scheduled with
+// AfterFunc more chance to run before AdvanceAndYield returns.
+func WithYields(n int) Option {
+	return func(fc *fakeClock) {
+		fc.yields = n
+	}
+}
+
+// WithWatchdog makes the FakeClock watch for sleepers which stay pending for
+// longer than d of real time, which usually means a test never advanced the
+// clock far enough. Each such sleeper is reported once per time it is armed,
+// by calling handler with its label and deadline, or by logging it if handler
+// is nil. The watchdog runs on a goroutine in real time, separate from the
+// FakeClock's timeline, which starts once all the options are applied and
+// runs until Close or Stop is called. Sleepers created after that are not
+// watched, even though the FakeClock remains usable after Close.
+func WithWatchdog(d time.Duration, handler func(label string, deadline time.Time)) Option {
+	return func(fc *fakeClock) {
+		if d <= 0 {
+			return
+		}
+		fc.watchdog = d
+		if handler == nil {
+			handler = func(label string, deadline time.Time) {
+				log.Printf("clockwork: %s due at %v still pending after %v", label, deadline, d)
+			}
+		}
+		fc.watchdogHandler = handler
+	}
+}
+
+// WithTickerLeakWarnings makes Close report each ticker it finds still
+// running, which the test should have stopped, by calling handler with the
+// ticker's period, or zero for a step ticker. If handler is nil, each is
+// logged instead. Close is the place to check, rather than a finalizer, as a
+// running ticker is referenced by its FakeClock and can't be collected first.
+func WithTickerLeakWarnings(handler func(period time.Duration)) Option {
+	return func(fc *fakeClock) {
+		if handler == nil {
+			handler = func(period time.Duration) {
+				log.Printf("clockwork: ticker with period %v was not stopped before Close", period)
+			}
+		}
+		fc.tickerLeaks = handler
+	}
+}
+
+// WithUnbufferedTimers gives the channels of the FakeClock's timers, from
+// After, AfterAt, NewTimer and NewTimerAt, no buffer, to test code which must
+// be receiving when a timer fires. A timer which fires sends its value from a
+// goroutine of its own, which waits for it to be received, so Advance and Set
+// don't block. Resetting the timer, or stopping it with WithGo123Timers,
+// abandons a send which is still waiting, as it would discard a buffered
+// value. Tickers are unaffected.
+func WithUnbufferedTimers() Option {
+	return func(fc *fakeClock) {
+		fc.unbufferedTimers = true
+	}
+}
+
+// WithCallbackRunner makes the FakeClock pass the functions scheduled with
+// AfterFunc to run when they become due, rather than running each on a new
+// goroutine. run is called during the Advance or Set which reaches them, once
+// the clock's lock is released, so it may call f straight away, hand it to
+// another goroutine, or queue it for one, such as a test's main goroutine.
+// AdvanceAndWait doesn't wait for functions passed to run. WithDeferredCallbacks
+// takes precedence over it.
+func WithCallbackRunner(run func(f func())) Option {
+	return func(fc *fakeClock) {
+		fc.callbackRunner = run
+	}
+}
+
+// WithMaxFiresPerAdvance limits the number of sleepers which a single Advance,
+// Set or one of their variants fires to n, to model cooperative scheduling in
+// simulations where many sleepers share a deadline. Due sleepers fire in
+// deadline order as usual, and those beyond the first n stay pending, to be
+// fired first by the next move of the clock, which may be Advance(0). Like any
+// sleeper, a deferred one fires with the time the clock has reached, rather
+// than its deadline. Ticks count towards the limit, but timers which are
+// already due when they are created still fire straight away. A non-positive
+// n means no limit.
+func WithMaxFiresPerAdvance(n int) Option {
+	return func(fc *fakeClock) {
+		if n > 0 {
+			fc.maxFires = n
+		}
+	}
+}
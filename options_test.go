@@ -0,0 +1,457 @@
+package clockwork
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mustPanic fails the test unless fn panics.
+func mustPanic(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s did not panic", name)
+		}
+	}()
+	fn()
+}
+
+// mustPanicWith fails the test unless fn panics with an error wrapping target.
+// It unwraps by hand rather than with errors.Is, to build with Go 1.11.
+func mustPanicWith(t *testing.T, name string, target error, fn func()) {
+	t.Helper()
+	defer func() {
+		got, _ := recover().(error)
+		err := got
+		for err != nil && err != target {
+			w, ok := err.(interface{ Unwrap() error })
+			if !ok {
+				break
+			}
+			err = w.Unwrap()
+		}
+		if err != target {
+			t.Errorf("%s panicked with %v, want an error wrapping %v", name, got, target)
+		}
+	}()
+	fn()
+}
+
+func TestWithMaxAdvance(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock(WithMaxAdvance(time.Hour))
+	start := fc.Now()
+
+	fc.Advance(time.Hour)
+	fc.Set(start)
+
+	mustPanicWith(t, "Advance beyond limit", ErrAdvanceLimit, func() { fc.Advance(time.Hour + 1) })
+	mustPanicWith(t, "Set forward beyond limit", ErrAdvanceLimit, func() { fc.Set(start.Add(time.Hour + 1)) })
+	mustPanicWith(t, "Set backward beyond limit", ErrAdvanceLimit, func() { fc.Set(start.Add(-time.Hour - 1)) })
+
+	if now := fc.Now(); !now.Equal(start) {
+		t.Errorf("rejected moves changed the clock: got %v, want %v", now, start)
+	}
+}
+
+func TestWithStrictTickers(t *testing.T) {
+	t.Parallel()
+	for _, test := range []struct {
+		name string
+		opts []Option
+		want int
+	}{
+		{name: "default"},
+		{name: "strict", opts: []Option{WithStrictTickers()}, want: 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fc := NewFakeClock(test.opts...)
+			ft := fc.NewTicker(time.Second)
+			defer ft.Stop()
+			fc.BlockUntil(1)
+
+			// The first tick is buffered, the second finds it unread.
+			fc.Advance(time.Second)
+			fc.BlockUntil(1)
+			fc.Advance(time.Second)
+			fc.BlockUntil(1)
+			if got := fc.TickerOverruns(); got != test.want {
+				t.Errorf("TickerOverruns() = %d, want %d", got, test.want)
+			}
+
+			// Once the consumer catches up, ticks are delivered again.
+			<-ft.Chan()
+			fc.Advance(time.Second)
+			fc.BlockUntil(1)
+			if got := fc.TickerOverruns(); got != test.want {
+				t.Errorf("TickerOverruns() after catching up = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+// TestWithGo123Timers mirrors the standard library's tests for the results of
+// Stop and Reset on a timer which has fired.
+func TestWithGo123Timers(t *testing.T) {
+	t.Parallel()
+	for _, go123 := range []bool{false, true} {
+		for _, stop := range []bool{false, true} {
+			for _, received := range []bool{false, true} {
+				var opts []Option
+				if go123 {
+					opts = append(opts, WithGo123Timers())
+				}
+				fc := NewFakeClock(opts...)
+				timer := fc.NewTimer(time.Second)
+				fc.Advance(time.Second)
+				if received {
+					<-timer.C()
+				}
+
+				var got bool
+				if stop {
+					got = timer.Stop()
+				} else {
+					got = timer.Reset(time.Second)
+				}
+				want := go123 && !received
+				if got != want {
+					t.Errorf("go123=%v stop=%v received=%v: got %v, want %v", go123, stop, received, got, want)
+				}
+
+				// Only Stop without WithGo123Timers leaves a value behind:
+				// Reset always discards it.
+				stale := len(timer.C()) > 0
+				if wantStale := !go123 && !received && stop; stale != wantStale {
+					t.Errorf("go123=%v stop=%v received=%v: stale value buffered = %v, want %v", go123, stop, received, stale, wantStale)
+				}
+			}
+		}
+	}
+}
+
+func TestWithGo123TimersTickerStop(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock(WithGo123Timers())
+	ft := fc.NewTicker(time.Second)
+	fc.BlockUntil(1)
+	fc.Advance(time.Second)
+	fc.BlockUntil(1)
+
+	ft.Stop()
+	select {
+	case tick := <-ft.Chan():
+		t.Errorf("received stale tick %v after Stop", tick)
+	default:
+	}
+}
+
+func TestWithDeferredCallbacks(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock(WithDeferredCallbacks())
+
+	var order []int
+	for i := 3; i > 0; i-- {
+		i := i
+		fc.AfterFunc(time.Duration(i)*time.Second, func() { order = append(order, i) })
+	}
+	fc.AfterFunc(0, func() { order = append(order, 0) })
+
+	fc.Advance(2 * time.Second)
+	if len(order) != 0 {
+		t.Fatalf("callbacks %v ran before RunPendingCallbacks", order)
+	}
+	if n := fc.RunPendingCallbacks(); n != 3 {
+		t.Errorf("RunPendingCallbacks() = %d, want 3", n)
+	}
+	if want := []int{0, 1, 2}; !reflect.DeepEqual(order, want) {
+		t.Errorf("callbacks ran in order %v, want %v", order, want)
+	}
+	if n := fc.RunPendingCallbacks(); n != 0 {
+		t.Errorf("RunPendingCallbacks() = %d with nothing due, want 0", n)
+	}
+
+	fc.AdvanceAndWait(time.Second)
+	if n := fc.RunPendingCallbacks(); n != 1 || order[len(order)-1] != 3 {
+		t.Errorf("RunPendingCallbacks() = %d after AdvanceAndWait, order %v", n, order)
+	}
+}
+
+func TestWithResolution(t *testing.T) {
+	t.Parallel()
+	const resolution = 15 * time.Millisecond
+	fc := NewFakeClock(WithResolution(resolution))
+	start := fc.Now()
+
+	timer := fc.NewTimer(20 * time.Millisecond)
+	for i := 1; i <= 4; i++ {
+		fc.Advance(7 * time.Millisecond)
+		elapsed := time.Duration(i) * 7 * time.Millisecond
+		if got, want := fc.Now(), start.Add(elapsed).Truncate(resolution); !got.Equal(want) {
+			t.Errorf("after %v, Now() = %v, want %v", elapsed, got, want)
+		}
+		if got := fc.Now().Sub(start) % resolution; got != 0 {
+			t.Errorf("after %v, Now() is %v past a %v boundary", elapsed, got, resolution)
+		}
+	}
+
+	// The timer fired at exactly 20ms, during the third advance, and reports
+	// the clock's exact time then.
+	select {
+	case got := <-timer.C():
+		if want := start.Add(21 * time.Millisecond); !got.Equal(want) {
+			t.Errorf("timer fired at %v, want %v", got, want)
+		}
+	default:
+		t.Errorf("timer did not fire")
+	}
+}
+
+func TestAdvanceAndYield(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock(WithYields(100))
+
+	var ran uint32
+	fc.AfterFunc(time.Second, func() { atomic.StoreUint32(&ran, 1) })
+	fc.AdvanceAndYield(time.Second)
+	if atomic.LoadUint32(&ran) == 0 {
+		t.Errorf("AfterFunc function did not run while AdvanceAndYield yielded")
+	}
+}
+
+func TestWithWatchdog(t *testing.T) {
+	t.Parallel()
+	type report struct {
+		label    string
+		deadline time.Time
+	}
+	reports := make(chan report, 10)
+	fc := NewFakeClock(WithWatchdog(10*time.Millisecond, func(label string, deadline time.Time) {
+		reports <- report{label, deadline}
+	}))
+	defer fc.Stop()
+
+	// A timer which fires promptly is never reported.
+	fc.NewTimer(time.Second)
+	fc.Advance(time.Second)
+	fc.NewTimer(time.Hour)
+	want := report{"NewTimer", fc.Now().Add(time.Hour)}
+
+	select {
+	case got := <-reports:
+		if got.label != want.label || !got.deadline.Equal(want.deadline) {
+			t.Errorf("watchdog reported %+v, want %+v", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("watchdog did not report the unfired timer")
+	}
+	// Each arming is reported once.
+	select {
+	case got := <-reports:
+		t.Errorf("watchdog reported %+v again", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWithWatchdogExits(t *testing.T) {
+	t.Parallel()
+	for name, stop := range map[string]func(FakeClock){
+		"Close": func(fc FakeClock) { fc.Close() },
+		"Stop":  func(fc FakeClock) { fc.Stop() },
+	} {
+		fc := NewFakeClock(WithWatchdog(time.Millisecond, func(string, time.Time) {}))
+		exited := fc.(*fakeClock).watchdogExited
+		stop(fc)
+		stop(fc)
+		select {
+		case <-exited:
+		case <-time.After(time.Second):
+			t.Errorf("watchdog still running after %s", name)
+		}
+	}
+}
+
+func TestWithTickerLeakWarnings(t *testing.T) {
+	t.Parallel()
+	var leaked []time.Duration
+	fc := NewFakeClock(WithTickerLeakWarnings(func(period time.Duration) {
+		leaked = append(leaked, period)
+	}))
+	fc.NewTicker(time.Second)
+	fc.NewTicker(time.Minute).Stop()
+	fc.NewStepTicker()
+
+	fc.Close()
+	if want := []time.Duration{time.Second, 0}; !reflect.DeepEqual(leaked, want) {
+		t.Errorf("leaked tickers reported with periods %v, want %v", leaked, want)
+	}
+	leaked = nil
+	fc.Close()
+	if len(leaked) != 0 {
+		t.Errorf("second Close reported %v", leaked)
+	}
+}
+
+func TestWithUnbufferedTimers(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock(WithUnbufferedTimers())
+	start := fc.Now()
+	timer := fc.NewTimer(time.Second)
+	if n := cap(timer.C()); n != 0 {
+		t.Fatalf("timer channel has capacity %d, want 0", n)
+	}
+
+	// Advance doesn't wait for the value to be received, and the value
+	// waits for the receiver.
+	fc.Advance(time.Second)
+	if n := len(timer.C()); n != 0 {
+		t.Errorf("%d values buffered after firing", n)
+	}
+	if v := <-timer.C(); !v.Equal(start.Add(time.Second)) {
+		t.Errorf("received %v, want %v", v, start.Add(time.Second))
+	}
+
+	// Reset abandons a value nobody received.
+	fc.Advance(time.Second)
+	timer.Reset(time.Second)
+	select {
+	case v := <-timer.C():
+		t.Errorf("received %v after Reset", v)
+	case <-time.After(10 * time.Millisecond):
+	}
+	fc.Advance(time.Second)
+	if v := <-timer.C(); !v.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("received %v after Reset, want %v", v, start.Add(3*time.Second))
+	}
+
+	// Sleep still returns.
+	slept := make(chan struct{})
+	go func() {
+		fc.Sleep(time.Second)
+		close(slept)
+	}()
+	fc.BlockUntil(1)
+	fc.Advance(time.Second)
+	<-slept
+}
+
+func TestWithCallbackRunner(t *testing.T) {
+	t.Parallel()
+	var queue []func()
+	fc := NewFakeClock(WithCallbackRunner(func(f func()) { queue = append(queue, f) }))
+	mainID := goroutineID()
+
+	var ran []uint64
+	for i := 1; i <= 2; i++ {
+		fc.AfterFunc(time.Duration(i)*time.Second, func() { ran = append(ran, goroutineID()) })
+	}
+	fc.AfterFunc(0, func() { ran = append(ran, goroutineID()) })
+	fc.Advance(time.Second)
+	if len(ran) != 0 {
+		t.Fatalf("callbacks ran before the runner's queue was drained")
+	}
+	if len(queue) != 2 {
+		t.Fatalf("runner was passed %d callbacks, want 2", len(queue))
+	}
+	fc.AdvanceAndWait(time.Second)
+	for _, f := range queue {
+		f()
+	}
+	if len(ran) != 3 {
+		t.Fatalf("%d callbacks ran, want 3", len(ran))
+	}
+	for i, id := range ran {
+		if id != mainID {
+			t.Errorf("callback %d ran on goroutine %d, want %d", i, id, mainID)
+		}
+	}
+}
+
+// goroutineID returns the ID of the calling goroutine, parsed from its stack
+// trace.
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	id, err := strconv.ParseUint(string(buf[:bytes.IndexByte(buf, ' ')]), 10, 64)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+func TestWithMaxFiresPerAdvance(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock(WithMaxFiresPerAdvance(3))
+	var fired []int
+	var timers []Timer
+	for i := 0; i < 10; i++ {
+		timers = append(timers, fc.NewTimer(time.Second))
+	}
+	later := fc.NewTimer(2 * time.Second)
+
+	// collect records which timers have fired, in order.
+	collect := func() {
+		for i, timer := range timers {
+			select {
+			case <-timer.C():
+				fired = append(fired, i)
+			default:
+			}
+		}
+	}
+	fc.Advance(time.Second)
+	collect()
+	if len(fired) != 3 {
+		t.Fatalf("%d timers fired by the first Advance, want 3", len(fired))
+	}
+	advances := 1
+	for len(fired) < len(timers) {
+		fc.Advance(0)
+		collect()
+		advances++
+	}
+	if advances != 4 {
+		t.Errorf("firing all the timers took %d advances, want 4", advances)
+	}
+	if want := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}; !reflect.DeepEqual(fired, want) {
+		t.Errorf("timers fired in order %v, want %v", fired, want)
+	}
+	select {
+	case <-later.C():
+		t.Errorf("later timer fired before its deadline")
+	default:
+	}
+}
+
+func TestNewFakeClockWith(t *testing.T) {
+	t.Parallel()
+	if got, want := NewFakeClockWith().Now(), NewFakeClock().Now(); !got.Equal(want) {
+		t.Errorf("NewFakeClockWith().Now() = %v, want %v", got, want)
+	}
+
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	loc := time.FixedZone("UTC+1", 60*60)
+	for _, opts := range [][]Option{
+		{WithStart(start), WithLocation(loc)},
+		{WithLocation(loc), WithStart(start)},
+		{WithStart(start.Add(time.Hour)), WithLocation(loc), WithStart(start)},
+	} {
+		fc := NewFakeClockWith(append(opts, WithMaxAdvance(time.Hour))...)
+		now := fc.Now()
+		if !now.Equal(start) || now.Location() != loc {
+			t.Errorf("Now() = %v, want %v in %v", now, start, loc)
+		}
+		mustPanicWith(t, "Advance beyond WithMaxAdvance", ErrAdvanceLimit, func() { fc.Advance(2 * time.Hour) })
+	}
+
+	fc := NewFakeClockAt(start, WithLocation(loc))
+	if now := fc.Now(); !now.Equal(start) || now.Location() != loc {
+		t.Errorf("NewFakeClockAt with WithLocation: Now() = %v, want %v in %v", now, start, loc)
+	}
+}
@@ -0,0 +1,16 @@
+package clockwork
+
+// ReadOnly returns a Clock which behaves exactly like c, but hides everything
+// else about it. Passing ReadOnly(fc) to code under test stops that code from
+// asserting its way to the FakeClock and calling Advance or Set, leaving the
+// test in sole control of time. Unlike the other wrappers in this package, the
+// result deliberately doesn't implement Unwrap or FakeWrapper, so AsFake and
+// IsFake don't see through it, and Base returns it unchanged.
+func ReadOnly(c Clock) Clock {
+	return readOnlyClock{c}
+}
+
+// readOnlyClock's method set is exactly Clock's.
+type readOnlyClock struct {
+	Clock
+}
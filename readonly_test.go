@@ -0,0 +1,35 @@
+package clockwork
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReadOnly(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	c := ReadOnly(fc)
+
+	if _, ok := c.(FakeClock); ok {
+		t.Errorf("ReadOnly clock implements FakeClock")
+	}
+	if IsFake(c) {
+		t.Errorf("IsFake(ReadOnly(fc)) = true, want false")
+	}
+	if Base(c) != c {
+		t.Errorf("Base(ReadOnly(fc)) unwrapped the clock")
+	}
+
+	// The wrapped FakeClock still drives it.
+	if now := c.Now(); !now.Equal(fc.Now()) {
+		t.Errorf("Now() = %v, want %v", now, fc.Now())
+	}
+	ch := c.After(time.Second)
+	fc.BlockUntil(1)
+	fc.Advance(time.Second)
+	select {
+	case <-ch:
+	default:
+		t.Errorf("After() did not fire when the FakeClock was advanced")
+	}
+}
@@ -0,0 +1,75 @@
+package clockwork
+
+import (
+	"sync"
+	"time"
+)
+
+// ScaledClock wraps a Clock so that the duration of a single chosen call to
+// After, Sleep, NewTimer, AfterFunc or their variants can be scaled, for example to make one
+// timeout much longer than the rest in an integration test.
+type ScaledClock struct {
+	Clock
+
+	l     sync.Mutex // Guards scale
+	scale float64    // applied to the next call; zero when none is pending
+}
+
+// NewScaledClock returns a ScaledClock wrapping base, with no scale pending.
+func NewScaledClock(base Clock) *ScaledClock {
+	return &ScaledClock{Clock: base}
+}
+
+// ScaleNext makes the next call to After, Sleep, NewTimer, AfterFunc or their
+// variants use its duration multiplied by factor. Calls after that are not scaled. Calling
+// ScaleNext again before the scale is used replaces it.
+func (c *ScaledClock) ScaleNext(factor float64) {
+	c.l.Lock()
+	defer c.l.Unlock()
+	c.scale = factor
+}
+
+// scaled returns d multiplied by the pending scale, if any, consuming it.
+func (c *ScaledClock) scaled(d time.Duration) time.Duration {
+	c.l.Lock()
+	defer c.l.Unlock()
+	if c.scale == 0 {
+		return d
+	}
+	d = time.Duration(float64(d) * c.scale)
+	c.scale = 0
+	return d
+}
+
+func (c *ScaledClock) After(d time.Duration) <-chan time.Time {
+	return c.Clock.After(c.scaled(d))
+}
+
+func (c *ScaledClock) Sleep(d time.Duration) {
+	c.Clock.Sleep(c.scaled(d))
+}
+
+func (c *ScaledClock) NewTimer(d time.Duration) Timer {
+	return c.Clock.NewTimer(c.scaled(d))
+}
+
+func (c *ScaledClock) AfterFunc(d time.Duration, f func()) Timer {
+	return c.Clock.AfterFunc(c.scaled(d), f)
+}
+
+func (c *ScaledClock) AfterFuncDone(d time.Duration, f func()) (Timer, <-chan struct{}) {
+	return afterFuncDone(c.AfterFunc, d, f)
+}
+
+func (c *ScaledClock) AfterString(s string) (<-chan time.Time, error) {
+	return afterString(c, s)
+}
+
+func (c *ScaledClock) NewTimerString(s string) (Timer, error) {
+	return newTimerString(c, s)
+}
+
+// Unwrap returns the wrapped Clock.
+func (c *ScaledClock) Unwrap() Clock {
+	return c.Clock
+}
@@ -0,0 +1,47 @@
+package clockwork
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScaledClock(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	c := NewScaledClock(fc)
+
+	before := c.NewTimer(time.Second)
+	c.ScaleNext(10)
+	slow := c.NewTimer(time.Second)
+	after := c.NewTimer(time.Second)
+	c.ScaleNext(0.5)
+	fast := c.AfterFunc(time.Second, func() {})
+	c.ScaleNext(3)
+	parsed, err := c.NewTimerString("1s")
+	if err != nil {
+		t.Fatalf("NewTimerString(%q) error: %v", "1s", err)
+	}
+	c.ScaleNext(10)
+	done, _ := c.AfterFuncDone(time.Second, func() {})
+	afterDone := c.NewTimer(time.Second)
+
+	for _, test := range []struct {
+		name  string
+		timer Timer
+		want  time.Duration
+	}{
+		{"before ScaleNext", before, time.Second},
+		{"scaled by 10", slow, 10 * time.Second},
+		{"after scaled call", after, time.Second},
+		{"scaled by 0.5", fast, 500 * time.Millisecond},
+		{"parsed and scaled by 3", parsed, 3 * time.Second},
+		{"AfterFuncDone scaled by 10", done, 10 * time.Second},
+		{"after scaled AfterFuncDone", afterDone, time.Second},
+	} {
+		deadline, _ := test.timer.Deadline()
+		if got := deadline.Sub(start); got != test.want {
+			t.Errorf("%s: timer due after %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package clockwork
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Scheduler runs functions at given times on a FakeClock, one at a time and in
+// order of their due times. It is a building block for simulating systems
+// which queue work to be done later.
+type Scheduler struct {
+	fc FakeClock
+
+	runL  sync.Mutex // held while running tasks, so that they run one at a time
+	l     sync.Mutex // guards tasks
+	tasks []*scheduledTask
+}
+
+type scheduledTask struct {
+	at time.Time
+	f  func()
+}
+
+// NewScheduler returns a Scheduler running tasks on fc.
+func NewScheduler(fc FakeClock) *Scheduler {
+	return &Scheduler{fc: fc}
+}
+
+// At schedules f to run when the clock reaches t. Tasks due at the same time
+// run in the order they were scheduled. Tasks run whenever the clock reaches
+// their due time, whether it is moved by RunUntil or otherwise; if t is not in
+// the future, f runs straight away on another goroutine.
+func (s *Scheduler) At(t time.Time, f func()) {
+	s.l.Lock()
+	s.tasks = append(s.tasks, &scheduledTask{at: t, f: f})
+	sort.SliceStable(s.tasks, func(i, j int) bool {
+		return s.tasks[i].at.Before(s.tasks[j].at)
+	})
+	s.l.Unlock()
+	s.fc.AfterFunc(t.Sub(s.fc.Now()), s.runDue)
+}
+
+// RunUntil advances the clock to t, stopping at the due time of each task on
+// the way so that every task runs, in order, with the clock showing its due
+// time. Tasks scheduled by other tasks are run too if they are due by t.
+// RunUntil returns once all tasks due by t have returned; it does not move the
+// clock backwards if t is in the past.
+func (s *Scheduler) RunUntil(t time.Time) {
+	for {
+		next, ok := s.next()
+		if !ok || next.After(t) {
+			break
+		}
+		if d := next.Sub(s.fc.Now()); d > 0 {
+			s.fc.AdvanceAndWait(d)
+		}
+		s.runDue()
+	}
+	if d := t.Sub(s.fc.Now()); d > 0 {
+		s.fc.AdvanceAndWait(d)
+	}
+}
+
+// next returns the due time of the earliest pending task.
+func (s *Scheduler) next() (time.Time, bool) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if len(s.tasks) == 0 {
+		return time.Time{}, false
+	}
+	return s.tasks[0].at, true
+}
+
+// runDue runs every task which is due at the clock's current time.
+func (s *Scheduler) runDue() {
+	s.runL.Lock()
+	defer s.runL.Unlock()
+	for f := s.popDue(); f != nil; f = s.popDue() {
+		f()
+	}
+}
+
+// popDue removes and returns the earliest task if it is due, or returns nil.
+func (s *Scheduler) popDue() func() {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if len(s.tasks) == 0 || !reached(s.fc.Now(), s.tasks[0].at) {
+		return nil
+	}
+	f := s.tasks[0].f
+	s.tasks[0] = nil
+	s.tasks = s.tasks[1:]
+	return f
+}
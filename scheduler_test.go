@@ -0,0 +1,49 @@
+package clockwork
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	s := NewScheduler(fc)
+
+	type run struct {
+		name string
+		at   time.Duration
+	}
+	var runs []run
+	task := func(name string) func() {
+		return func() { runs = append(runs, run{name, fc.Since(start)}) }
+	}
+
+	// Tasks are inserted out of order, and one schedules another.
+	s.At(start.Add(3*time.Second), task("c"))
+	s.At(start.Add(time.Second), func() {
+		task("a")()
+		s.At(start.Add(2*time.Second), task("b2"))
+	})
+	s.At(start.Add(2*time.Second), task("b1"))
+	s.At(start.Add(5*time.Second), task("late"))
+
+	withTimeout(t, time.Second, func() {
+		s.RunUntil(start.Add(4 * time.Second))
+	})
+
+	want := []run{
+		{"a", time.Second},
+		{"b1", 2 * time.Second},
+		{"b2", 2 * time.Second},
+		{"c", 3 * time.Second},
+	}
+	if !reflect.DeepEqual(runs, want) {
+		t.Errorf("got runs %v, want %v", runs, want)
+	}
+	if got := fc.Since(start); got != 4*time.Second {
+		t.Errorf("clock advanced %v, want 4s", got)
+	}
+}
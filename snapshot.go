@@ -0,0 +1,93 @@
+package clockwork
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Snapshot is a checkpoint of a FakeClock's timeline: its time, and the
+// sleepers pending at that time with their deadlines. See FakeClock.Snapshot.
+type Snapshot struct {
+	fc       *fakeClock
+	time     time.Time
+	sleepers []sleeperState
+}
+
+type sleeperState struct {
+	s     *sleeper
+	until time.Time
+}
+
+// Snapshot captures the fakeClock's time and pending sleepers, for Restore.
+func (fc *fakeClock) Snapshot() Snapshot {
+	fc.l.RLock()
+	defer fc.l.RUnlock()
+	snap := Snapshot{
+		fc:       fc,
+		time:     fc.time,
+		sleepers: make([]sleeperState, len(fc.sleepers)),
+	}
+	for i, s := range fc.sleepers {
+		snap.sleepers[i] = sleeperState{s: s, until: s.Until()}
+	}
+	return snap
+}
+
+// Restore returns the fakeClock to snap: it sets the time back, stops any
+// sleepers created since, and re-arms the sleepers which were pending then
+// with their deadlines then, whether they have since fired, been stopped,
+// been Paused or been Reset. Values buffered in the channels of re-armed
+// timers are discarded, and goroutines blocked in Sleep on a stopped sleeper
+// are woken, as by Close.
+//
+// Restore is about the shape of the timeline rather than the state of the
+// code using the clock: values already received from channels, and functions
+// already run by AfterFunc, can't be taken back, and re-armed sleepers fire
+// again if the clock is advanced past them again. A Sleep which has returned
+// can't be resumed, so its sleeper is not re-armed. Tickers keep their own
+// schedule, which is not restored.
+//
+// Restore panics if snap was taken from a different clock.
+func (fc *fakeClock) Restore(snap Snapshot) {
+	if snap.fc != fc {
+		panic(errors.New("snapshot taken from a different FakeClock"))
+	}
+	fc.l.Lock()
+	defer fc.l.Unlock()
+
+	inSnapshot := make(map[*sleeper]bool, len(snap.sleepers))
+	for _, st := range snap.sleepers {
+		inSnapshot[st.s] = true
+	}
+	// Tickers' sleepers are left as they are.
+	var sleepers []*sleeper
+	for _, s := range append([]*sleeper(nil), fc.sleepers...) {
+		if s.ticker != nil {
+			sleepers = append(sleepers, s)
+		} else if !inSnapshot[s] && fc.stopTimerLocked(s) {
+			s.wakeLocked()
+		}
+	}
+
+	for _, st := range snap.sleepers {
+		s := st.s
+		if s.ticker != nil {
+			continue
+		}
+		if atomic.LoadUint32(&s.done) != 0 {
+			if s.kind == sleepSleeper {
+				continue
+			}
+			s.drain()
+			// A sleeper Paused since the snapshot is re-armed as it was.
+			s.paused = false
+			atomic.StoreUint32(&s.done, 0)
+		}
+		s.SetUntil(st.until)
+		sleepers = append(sleepers, s)
+	}
+	fc.sleepers = sleepers
+	fc.blockers = notifyBlockers(fc.blockers, len(fc.sleepers))
+	fc.setTime(snap.time)
+}
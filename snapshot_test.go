@@ -0,0 +1,129 @@
+package clockwork
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+
+	first := fc.NewTimer(time.Second)
+	second := fc.NewTimer(3 * time.Second)
+	var calls int32
+	fc.AfterFunc(2*time.Second, func() { atomic.AddInt32(&calls, 1) })
+	snap := fc.Snapshot()
+
+	// Scenario one: everything fires, and a new timer is created.
+	fc.AdvanceAndWait(5 * time.Second)
+	later := fc.NewTimer(time.Second)
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("AfterFunc ran %d times, want 1", got)
+	}
+
+	fc.Restore(snap)
+	if now := fc.Now(); !now.Equal(start) {
+		t.Errorf("Restore set the time to %v, want %v", now, start)
+	}
+	if IsActive(later) {
+		t.Errorf("timer created after the snapshot is still pending")
+	}
+	fc.BlockUntil(3)
+	for name, timer := range map[string]Timer{"first": first, "second": second} {
+		if n := len(timer.C()); n != 0 {
+			t.Errorf("%s timer has %d stale values after Restore", name, n)
+		}
+	}
+
+	// Scenario two: only the first second passes.
+	fc.Advance(time.Second)
+	select {
+	case got := <-first.C():
+		if want := start.Add(time.Second); !got.Equal(want) {
+			t.Errorf("first timer fired at %v, want %v", got, want)
+		}
+	default:
+		t.Errorf("first timer did not fire again after Restore")
+	}
+	if !IsActive(second) {
+		t.Errorf("second timer fired early")
+	}
+
+	// The AfterFunc is re-armed as well.
+	fc.AdvanceAndWait(time.Second)
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("AfterFunc ran %d times, want 2", got)
+	}
+}
+
+func TestRestoreWakesSleep(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	snap := fc.Snapshot()
+
+	slept := make(chan struct{})
+	go func() {
+		defer close(slept)
+		fc.Sleep(time.Hour)
+	}()
+	fc.BlockUntil(1)
+
+	fc.Restore(snap)
+	withTimeout(t, time.Second, func() { <-slept })
+}
+
+func TestRestorePausedTimer(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	timer := fc.NewTimer(time.Second)
+	snap := fc.Snapshot()
+
+	timer.(PausableTimer).Pause()
+	fc.Restore(snap)
+	if !timer.Stop() {
+		t.Fatalf("Stop() = false for a timer re-armed by Restore")
+	}
+	if timer.Stop() {
+		t.Errorf("second Stop() = true; the timer is still paused")
+	}
+	fc.Advance(time.Second)
+	select {
+	case got := <-timer.C():
+		t.Errorf("stopped timer fired at %v", got)
+	default:
+	}
+}
+
+func TestRestoreOtherClock(t *testing.T) {
+	t.Parallel()
+	snap := NewFakeClock().Snapshot()
+	mustPanic(t, "Restore from another clock", func() { NewFakeClock().Restore(snap) })
+}
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	fc.Advance(time.Hour)
+	timer := fc.NewTimer(time.Second)
+
+	clone := fc.Clone()
+	if got, want := clone.Now(), fc.Now(); !got.Equal(want) {
+		t.Errorf("clone Now() = %v, want %v", got, want)
+	}
+	clone.Advance(time.Minute)
+	if got, want := clone.Now(), fc.Now().Add(time.Minute); !got.Equal(want) {
+		t.Errorf("clone Now() after Advance = %v, want %v", got, want)
+	}
+	select {
+	case <-timer.C():
+		t.Errorf("original's timer fired when the clone was advanced")
+	default:
+	}
+	fc.Advance(time.Second)
+	if clone.Now().Equal(fc.Now()) {
+		t.Errorf("clone moved when the original was advanced")
+	}
+}
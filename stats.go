@@ -0,0 +1,57 @@
+package clockwork
+
+import "sync/atomic"
+
+// ClockStats holds cumulative counts of a FakeClock's activity, for asserting
+// how timer-heavy code uses the clock. See FakeClock.Stats.
+type ClockStats struct {
+	// TimersCreated counts the timers created by After, AfterAt, AfterFunc,
+	// NewTimer, NewTimerAt, Sleep and SleepUntil. Resetting a timer doesn't
+	// count.
+	TimersCreated int
+	// TimersFired counts the timers which have fired. A timer which is Reset
+	// and fires again counts each time.
+	TimersFired int
+	// TimersStopped counts the calls to a timer's Stop method which stopped
+	// it.
+	TimersStopped int
+	// TickersCreated counts the tickers created by NewTicker and its
+	// variants. Ticks don't count as timers firing.
+	TickersCreated int
+	// AdvanceCalls counts the times the clock was advanced: by Advance and
+	// AdvanceAndWait, and by each step of AdvanceUntil and AdvanceSteps.
+	AdvanceCalls int
+}
+
+// stats holds the counters behind ClockStats. Its fields are accessed
+// atomically, so that counting doesn't need the clock's lock.
+type stats struct {
+	timersCreated  uint64
+	timersFired    uint64
+	timersStopped  uint64
+	tickersCreated uint64
+	advanceCalls   uint64
+}
+
+// count increments one of the fakeClock's stats counters.
+func count(counter *uint64) {
+	atomic.AddUint64(counter, 1)
+}
+
+// countTimer increments a stats counter for s, unless it belongs to a ticker.
+func (s *sleeper) countTimer(counter *uint64) {
+	if s.kind != tickerSleeper {
+		count(counter)
+	}
+}
+
+// Stats returns the fakeClock's activity counters.
+func (fc *fakeClock) Stats() ClockStats {
+	return ClockStats{
+		TimersCreated:  int(atomic.LoadUint64(&fc.stats.timersCreated)),
+		TimersFired:    int(atomic.LoadUint64(&fc.stats.timersFired)),
+		TimersStopped:  int(atomic.LoadUint64(&fc.stats.timersStopped)),
+		TickersCreated: int(atomic.LoadUint64(&fc.stats.tickersCreated)),
+		AdvanceCalls:   int(atomic.LoadUint64(&fc.stats.advanceCalls)),
+	}
+}
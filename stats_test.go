@@ -0,0 +1,41 @@
+package clockwork
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+
+	fc.After(time.Second)
+	timer := fc.NewTimer(time.Second)
+	stopped := fc.NewTimer(time.Second)
+	ran := make(chan struct{})
+	fc.AfterFunc(time.Second, func() { close(ran) })
+	ticker := fc.NewTicker(time.Second)
+	defer ticker.Stop()
+	fc.BlockUntil(5)
+
+	stopped.Stop()
+	stopped.Stop() // already stopped, so not counted again
+	timer.Reset(time.Second)
+	fc.Advance(time.Second)
+	<-ran
+	fc.BlockUntil(1)
+	fc.Advance(time.Second)
+	fc.BlockUntil(1)
+	fc.Set(fc.Now())
+
+	want := ClockStats{
+		TimersCreated:  4,
+		TimersFired:    3,
+		TimersStopped:  1,
+		TickersCreated: 1,
+		AdvanceCalls:   2,
+	}
+	if got := fc.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
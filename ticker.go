@@ -1,6 +1,8 @@
 package clockwork
 
 import (
+	"errors"
+	"sync"
 	"time"
 )
 
@@ -13,61 +15,339 @@ type Ticker interface {
 	Stop()
 }
 
+var (
+	// ErrNonPositiveInterval is returned by TryNewTicker, and is the value
+	// NewTicker and its variants panic with, when the interval is not
+	// positive. Its message matches the panic from time.NewTicker.
+	ErrNonPositiveInterval = errors.New("non-positive interval for NewTicker")
+	// ErrNonPositiveBurst is the value NewBurstTicker panics with when the
+	// number of ticks is not positive.
+	ErrNonPositiveBurst = errors.New("non-positive tick count for NewBurstTicker")
+	// ErrNonPositiveBuffer is the value NewBufferedTicker panics with when
+	// the buffer size is not positive.
+	ErrNonPositiveBuffer = errors.New("non-positive buffer size for NewBufferedTicker")
+)
+
 type realTicker struct{ *time.Ticker }
 
 func (rt *realTicker) Chan() <-chan time.Time {
 	return rt.C
 }
 
-type fakeTicker struct {
+// forwardingTicker is a real Ticker whose ticks are forwarded to c by a
+// goroutine reading from a time.Ticker or time.Timer, dropping any which find
+// c full. The real clock's variants of NewTicker differ only in what that
+// goroutine does.
+type forwardingTicker struct {
 	c      chan time.Time
-	stop   chan bool
-	clock  FakeClock
-	period time.Duration
+	onStop func() // stops the source straight away, if set
+
+	stopOnce sync.Once
+	stop     chan struct{}
 }
 
-func (ft *fakeTicker) Chan() <-chan time.Time {
+func newForwardingTicker(buf int, onStop func()) *forwardingTicker {
+	return &forwardingTicker{
+		c:      make(chan time.Time, buf),
+		onStop: onStop,
+		stop:   make(chan struct{}),
+	}
+}
+
+func (ft *forwardingTicker) Chan() <-chan time.Time {
 	return ft.c
 }
 
-func (ft *fakeTicker) Stop() {
-	ft.stop <- true
+func (ft *forwardingTicker) Stop() {
+	if ft.onStop != nil {
+		ft.onStop()
+	}
+	ft.stopOnce.Do(func() { close(ft.stop) })
+}
+
+// receive waits for a tick from src. It reports false if the ticker is
+// stopped first.
+func (ft *forwardingTicker) receive(src <-chan time.Time) (time.Time, bool) {
+	select {
+	case <-ft.stop:
+		return time.Time{}, false
+	case tick := <-src:
+		return tick, true
+	}
+}
+
+// offer sends tick on c unless c is full, and reports whether it did.
+func (ft *forwardingTicker) offer(tick time.Time) bool {
+	select {
+	case ft.c <- tick:
+		return true
+	default:
+		return false
+	}
 }
 
-// runTickThread initializes a background goroutine to send the tick time to the ticker channel
-// after every period. Tick events are discarded if the underlying ticker channel does not have
-// enough capacity.
-func (ft *fakeTicker) runTickThread() {
-	nextTick := ft.clock.Now().Add(ft.period)
-	next := ft.clock.After(ft.period)
+// forward starts a goroutine which forwards every tick from src until the
+// ticker is stopped.
+func (ft *forwardingTicker) forward(src <-chan time.Time) {
 	go func() {
 		for {
-			select {
-			case <-ft.stop:
+			tick, ok := ft.receive(src)
+			if !ok {
+				return
+			}
+			ft.offer(tick)
+		}
+	}()
+}
+
+// newRealImmediateTicker wraps t, delivering an additional tick when it is
+// created.
+func newRealImmediateTicker(t *time.Ticker, now time.Time) *forwardingTicker {
+	ft := newForwardingTicker(1, t.Stop)
+	ft.c <- now
+	ft.forward(t.C)
+	return ft
+}
+
+// newRealBurstTicker wraps t, forwarding n ticks and then stopping it.
+func newRealBurstTicker(t *time.Ticker, n int) *forwardingTicker {
+	ft := newForwardingTicker(1, t.Stop)
+	go func() {
+		defer t.Stop()
+		for n > 0 {
+			tick, ok := ft.receive(t.C)
+			if !ok {
+				return
+			}
+			if ft.offer(tick) {
+				n--
+			}
+		}
+	}()
+	return ft
+}
+
+// newRealBufferedTicker wraps t, forwarding its ticks to a channel holding up
+// to buf of them.
+func newRealBufferedTicker(t *time.Ticker, buf int) *forwardingTicker {
+	ft := newForwardingTicker(buf, t.Stop)
+	ft.forward(t.C)
+	return ft
+}
+
+// newRealAlignedTicker waits for first to reach the first boundary, then
+// ticks at it and every period after it, using a time.Ticker started there.
+func newRealAlignedTicker(first *time.Timer, period time.Duration) *forwardingTicker {
+	ft := newForwardingTicker(1, nil)
+	go func() {
+		defer first.Stop()
+		tick, ok := ft.receive(first.C)
+		if !ok {
+			return
+		}
+		t := time.NewTicker(period)
+		defer t.Stop()
+		for ok {
+			ft.offer(tick)
+			tick, ok = ft.receive(t.C)
+		}
+	}()
+	return ft
+}
+
+// newRealSkewedTicker ticks at the times of a skewed ticker, using a
+// time.Timer re-armed for each tick. See Clock.NewSkewedTicker.
+func newRealSkewedTicker(start time.Time, period time.Duration, skew func(n int) time.Duration) *forwardingTicker {
+	ft := newForwardingTicker(1, nil)
+	go func() {
+		var t *time.Timer
+		for n := 1; ; n++ {
+			d := time.Until(skewedTick(start, period, skew, n))
+			if d <= 0 {
+				continue
+			}
+			if t == nil {
+				t = time.NewTimer(d)
+				defer t.Stop()
+			} else {
+				t.Reset(d)
+			}
+			tick, ok := ft.receive(t.C)
+			if !ok {
 				return
-			case <-next:
-				// We send the time that the tick was supposed to occur at.
-				tick := nextTick
-				// Before sending the tick, we'll compute the next tick time and start the clock.After
-				// call.
-				now := ft.clock.Now()
-				// First, figure out how many periods there have been between "now" and the time we were
-				// supposed to have triggered, then advance over all of those.
-				skipTicks := (now.Sub(tick) + ft.period - 1) / ft.period
-				nextTick = nextTick.Add(skipTicks * ft.period)
-				// Now, keep advancing until we are past now. This should happen at most once.
-				for !nextTick.After(now) {
-					nextTick = nextTick.Add(ft.period)
-				}
-				// Figure out how long between now and the next scheduled tick, then wait that long.
-				remaining := nextTick.Sub(now)
-				next = ft.clock.After(remaining)
-				// Finally, we can actually send the tick.
-				select {
-				case ft.c <- tick:
-				default:
-				}
 			}
+			ft.offer(tick)
 		}
 	}()
+	return ft
+}
+
+// fakeTicker is driven by its clock: its sleeper, next, stays registered with
+// the clock while the ticker runs, and each time it falls due the clock
+// delivers the tick and re-arms it within the same Advance or Set, so no
+// goroutine is involved.
+type fakeTicker struct {
+	c      chan time.Time
+	clock  *fakeClock
+	period time.Duration
+	next   *sleeper // due at the next tick
+
+	l         sync.Mutex // Guards stopped and remaining, and is held while sending ticks
+	stopped   bool
+	remaining int // ticks left to deliver before stopping; zero for no limit
+
+	// skew is set for a ticker from NewSkewedTicker, whose nth tick is due
+	// at start plus n periods plus skew(n). n is the number of the next
+	// tick. start, which Jump moves, and n are guarded by the clock's lock.
+	skew  func(n int) time.Duration
+	start time.Time
+	n     int
+}
+
+func (ft *fakeTicker) Chan() <-chan time.Time {
+	return ft.c
+}
+
+// Stop turns off the ticker. Stop takes effect as soon as it is called: a tick
+// which falls due during a concurrent Advance is only delivered if it was sent
+// before Stop was called, and no further ticks are sent however far the clock
+// is advanced. It is safe to call Stop more than once.
+func (ft *fakeTicker) Stop() {
+	ft.l.Lock()
+	ft.stopped = true
+	ft.l.Unlock()
+
+	fc := ft.clock
+	fc.l.Lock()
+	fc.stopTimerLocked(ft.next)
+	fc.removeTickerLocked(ft)
+	fc.l.Unlock()
+	if fc.go123Timers {
+		drain(ft.c)
+	}
+}
+
+// send delivers a tick, unless the ticker has been stopped. Tick events are
+// discarded if the channel is full. It reports whether the ticker is now
+// stopped, either by Stop or because it has delivered all the ticks it was
+// limited to.
+func (ft *fakeTicker) send(tick time.Time) (stopped bool) {
+	ft.l.Lock()
+	defer ft.l.Unlock()
+	if ft.stopped {
+		return true
+	}
+	select {
+	case ft.c <- tick:
+		if ft.remaining > 0 {
+			ft.remaining--
+			if ft.remaining == 0 {
+				ft.stopped = true
+				return true
+			}
+		}
+	default:
+		ft.clock.tickerOverrun()
+	}
+	return false
+}
+
+// tick delivers the ticks due when the clock reaches now, starting with the
+// one at first, and returns the time of the next tick. It reports whether the
+// ticker is stopped, in which case there is no next tick. The clock calls it
+// with fc.l held.
+//
+// Tick times are anchored to the first tick: the nth tick after it is always
+// due exactly n periods later, however the clock is advanced in between. Each
+// tick carries the time it was due, not the time the clock reached.
+func (ft *fakeTicker) tick(first, now time.Time) (next time.Time, stopped bool) {
+	if ft.skew != nil {
+		if ft.send(first) {
+			return time.Time{}, true
+		}
+		return ft.nextSkewed(now), false
+	}
+	tick := first
+	if ft.send(tick) {
+		return time.Time{}, true
+	}
+	// A buffered ticker is also sent the ticks for periods which have already
+	// elapsed in full, as long as there is room for them.
+	for i := 1; i < cap(ft.c); i++ {
+		if tick.Add(ft.period).After(now) {
+			break
+		}
+		tick = tick.Add(ft.period)
+		if ft.send(tick) {
+			return time.Time{}, true
+		}
+	}
+	// Any other periods which have already elapsed in full are skipped.
+	skipTicks := now.Sub(tick)/ft.period + 1
+	return tick.Add(skipTicks * ft.period), false
+}
+
+// nextSkewed moves on to the first tick of a skewed ticker due after now, and
+// returns its time.
+func (ft *fakeTicker) nextSkewed(now time.Time) time.Time {
+	for {
+		ft.n++
+		if tick := skewedTick(ft.start, ft.period, ft.skew, ft.n); tick.After(now) {
+			return tick
+		}
+	}
+}
+
+// skewedTick returns the time the nth tick of a skewed ticker started at start
+// is due.
+func skewedTick(start time.Time, period time.Duration, skew func(n int) time.Duration, n int) time.Time {
+	return start.Add(time.Duration(n)*period + skew(n))
+}
+
+// stepTicker ticks each time its clock is moved. See FakeClock.NewStepTicker.
+type stepTicker struct {
+	c     chan time.Time
+	clock *fakeClock
+
+	l       sync.Mutex // Guards stopped, and is held while sending ticks
+	stopped bool
+}
+
+func (st *stepTicker) Chan() <-chan time.Time {
+	return st.c
+}
+
+// Stop turns off the ticker. Like fakeTicker.Stop, it takes effect as soon as
+// it is called, and it is safe to call more than once.
+func (st *stepTicker) Stop() {
+	st.l.Lock()
+	st.stopped = true
+	st.l.Unlock()
+
+	fc := st.clock
+	fc.l.Lock()
+	for i, other := range fc.stepTickers {
+		if other == st {
+			fc.stepTickers = append(fc.stepTickers[:i:i], fc.stepTickers[i+1:]...)
+			break
+		}
+	}
+	fc.l.Unlock()
+	if fc.go123Timers {
+		drain(st.c)
+	}
+}
+
+// send delivers a tick, unless the ticker has been stopped.
+func (st *stepTicker) send(tick time.Time) {
+	st.l.Lock()
+	defer st.l.Unlock()
+	if st.stopped {
+		return
+	}
+	select {
+	case st.c <- tick:
+	default:
+		st.clock.tickerOverrun()
+	}
 }
@@ -1,6 +1,9 @@
 package clockwork
 
 import (
+	"runtime"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -60,6 +63,46 @@ func TestFakeTickerTick(t *testing.T) {
 	ft.Stop()
 }
 
+// TestFakeTickerSynchronous checks that ticks are delivered within Advance, by
+// the clock rather than by a goroutine per ticker. It counts goroutines, so it
+// doesn't run in parallel with other tests.
+func TestFakeTickerSynchronous(t *testing.T) {
+	fc := NewFakeClock()
+	before := runtime.NumGoroutine()
+	tickers := make([]Ticker, 100)
+	for i := range tickers {
+		tickers[i] = fc.NewTicker(time.Duration(i+1) * time.Second)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines running after creating tickers, want at most %d", n, before)
+	}
+
+	start := fc.Now()
+	for step := 1; step <= 3; step++ {
+		fc.Advance(time.Second)
+		// No BlockUntil: the ticks are already buffered when Advance returns.
+		for i, ticker := range tickers {
+			period := time.Duration(i+1) * time.Second
+			select {
+			case tick := <-ticker.Chan():
+				if elapsed := tick.Sub(start); elapsed%period != 0 || elapsed > time.Duration(step)*time.Second {
+					t.Errorf("step %d: ticker %d ticked at %v", step, i, elapsed)
+				}
+			default:
+				if step%(i+1) == 0 {
+					t.Errorf("step %d: ticker %d did not tick", step, i)
+				}
+			}
+		}
+	}
+	for _, ticker := range tickers {
+		ticker.Stop()
+	}
+	if n := fc.ActiveTickers(); n != 0 {
+		t.Errorf("ActiveTickers() = %d after stopping them all, want 0", n)
+	}
+}
+
 func TestFakeTicker_Race(t *testing.T) {
 	t.Parallel()
 	fc := NewFakeClock()
@@ -90,3 +133,602 @@ func TestFakeTicker_Race2(t *testing.T) {
 	}
 	ft.Stop()
 }
+
+func TestFakeTickerFractionalAdvance(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+
+	ft := fc.NewTicker(100 * time.Millisecond)
+	defer ft.Stop()
+	fc.BlockUntil(1)
+
+	// Ticks are due after the 4th (120ms), 7th (210ms) and 10th (300ms)
+	// advance, and must stay aligned to multiples of 100ms.
+	wantTicks := map[int]time.Duration{
+		4:  100 * time.Millisecond,
+		7:  200 * time.Millisecond,
+		10: 300 * time.Millisecond,
+	}
+	for i := 1; i <= 10; i++ {
+		fc.Advance(30 * time.Millisecond)
+		fc.BlockUntil(1)
+
+		want, ok := wantTicks[i]
+		if !ok {
+			select {
+			case tick := <-ft.Chan():
+				t.Fatalf("advance %d: unexpected tick at %v", i, tick.Sub(start))
+			default:
+			}
+			continue
+		}
+		select {
+		case tick := <-ft.Chan():
+			if got := tick.Sub(start); got != want {
+				t.Errorf("advance %d: got tick at %v, want %v", i, got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("advance %d: expected tick", i)
+		}
+	}
+}
+
+func TestFakeTickerStopIdempotent(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+
+	ft := fc.NewTicker(time.Second)
+	fc.BlockUntil(1)
+	withTimeout(t, 100*time.Millisecond, func() {
+		ft.Stop()
+		ft.Stop()
+	})
+	// The ticker's pending sleeper is released by Stop.
+	fc.BlockUntil(0)
+
+	fc.Advance(5 * time.Second)
+	select {
+	case tick := <-ft.Chan():
+		t.Errorf("received tick %v after Stop", tick)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestFakeTickerImmediate(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+
+	ft := fc.NewTickerImmediate(time.Second)
+	defer ft.Stop()
+	fc.BlockUntil(1)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case tick := <-ft.Chan():
+			if want := start.Add(time.Duration(i) * time.Second); !tick.Equal(want) {
+				t.Errorf("tick %d at %v, want %v", i, tick, want)
+			}
+		default:
+			t.Fatalf("expected tick %d", i)
+		}
+		fc.Advance(time.Second)
+		fc.BlockUntil(1)
+	}
+}
+
+func TestRealTickerImmediate(t *testing.T) {
+	t.Parallel()
+	rc := NewRealClock()
+	start := rc.Now()
+
+	rt := rc.NewTickerImmediate(10 * time.Millisecond)
+	defer rt.Stop()
+	select {
+	case <-rt.Chan():
+	default:
+		t.Fatalf("expected an immediate tick")
+	}
+	select {
+	case tick := <-rt.Chan():
+		if elapsed := tick.Sub(start); elapsed < 10*time.Millisecond {
+			t.Errorf("second tick after %v, want at least 10ms", elapsed)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected a periodic tick")
+	}
+}
+
+func TestFakeTickerStopDuringAdvance(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	ft := fc.NewTicker(time.Millisecond)
+	fc.BlockUntil(1)
+
+	var done uint32
+	advancing := make(chan struct{})
+	go func() {
+		defer close(advancing)
+		for atomic.LoadUint32(&done) == 0 {
+			fc.Advance(time.Millisecond)
+		}
+	}()
+
+	// Consume a few ticks, then stop the ticker while the clock is moving.
+	for i := 0; i < 3; i++ {
+		<-ft.Chan()
+	}
+	ft.Stop()
+	stoppedAt := fc.Now()
+
+	// At most one tick, sent before Stop was called, can still be buffered.
+	select {
+	case tick := <-ft.Chan():
+		if tick.After(stoppedAt) {
+			t.Errorf("buffered tick %v is after the Stop instant %v", tick, stoppedAt)
+		}
+	default:
+	}
+
+	fc.Advance(time.Second)
+	atomic.StoreUint32(&done, 1)
+	<-advancing
+	select {
+	case tick := <-ft.Chan():
+		t.Errorf("received tick %v after Stop", tick)
+	default:
+	}
+}
+
+func TestTryNewTicker(t *testing.T) {
+	t.Parallel()
+	clocks := map[string]Clock{
+		"real": NewRealClock(),
+		"fake": NewFakeClock(),
+	}
+	for name, c := range clocks {
+		for _, d := range []time.Duration{0, -time.Second} {
+			ticker, err := c.TryNewTicker(d)
+			if err != ErrNonPositiveInterval || ticker != nil {
+				t.Errorf("%s: TryNewTicker(%v) = %v, %v, want %v", name, d, ticker, err, ErrNonPositiveInterval)
+			}
+		}
+		ticker, err := c.TryNewTicker(time.Second)
+		if err != nil || ticker == nil {
+			t.Fatalf("%s: TryNewTicker(1s) = %v, %v, want a ticker", name, ticker, err)
+		}
+		ticker.Stop()
+	}
+}
+
+func TestNewTickerPanicMessage(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		err, _ := recover().(error)
+		if err != ErrNonPositiveInterval || err.Error() != "non-positive interval for NewTicker" {
+			t.Errorf("NewTicker(0) panicked with %v, want %v with the time.NewTicker message", err, ErrNonPositiveInterval)
+		}
+	}()
+	NewFakeClock().NewTicker(0)
+}
+
+func TestFakeBurstTicker(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	const n = 3
+
+	ft := fc.NewBurstTicker(time.Second, n)
+	defer ft.Stop()
+	ticks := 0
+	for i := 0; i < n; i++ {
+		fc.BlockUntil(1)
+		fc.Advance(time.Second)
+		select {
+		case <-ft.Chan():
+			ticks++
+		case <-time.After(time.Second):
+			t.Fatalf("expected tick %d", i)
+		}
+	}
+	// Having stopped itself, the ticker has no pending sleeper, and
+	// advancing through n more periods delivers nothing.
+	fc.BlockUntil(0)
+	for i := 0; i < n; i++ {
+		fc.Advance(time.Second)
+	}
+	select {
+	case tick := <-ft.Chan():
+		t.Errorf("received tick %v after the burst", tick)
+	case <-time.After(10 * time.Millisecond):
+	}
+	if ticks != n {
+		t.Errorf("got %d ticks, want %d", ticks, n)
+	}
+}
+
+func TestRealBurstTicker(t *testing.T) {
+	t.Parallel()
+	rt := NewRealClock().NewBurstTicker(time.Millisecond, 2)
+	defer rt.Stop()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-rt.Chan():
+		case <-time.After(time.Second):
+			t.Fatalf("expected tick %d", i)
+		}
+	}
+	select {
+	case tick := <-rt.Chan():
+		t.Errorf("received tick %v after the burst", tick)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestNewBurstTickerNonPositive(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if err := recover(); err != ErrNonPositiveBurst {
+			t.Errorf("NewBurstTicker(1s, 0) panicked with %v, want %v", err, ErrNonPositiveBurst)
+		}
+	}()
+	mustPanic(t, "real NewBurstTicker(1s, -1)", func() { NewRealClock().NewBurstTicker(time.Second, -1) })
+	NewFakeClock().NewBurstTicker(time.Second, 0)
+}
+
+func TestFakeBufferedTicker(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	ft := fc.NewBufferedTicker(time.Second, 5)
+	defer ft.Stop()
+	fc.BlockUntil(1)
+
+	// Skipping seven periods at once delivers a tick for each of the first
+	// five, which fill the buffer.
+	fc.Advance(7 * time.Second)
+	fc.BlockUntil(1)
+	if n := len(ft.Chan()); n != 5 {
+		t.Fatalf("got %d buffered ticks, want 5", n)
+	}
+	for i := 1; i <= 5; i++ {
+		if tick, want := <-ft.Chan(), start.Add(time.Duration(i)*time.Second); !tick.Equal(want) {
+			t.Errorf("tick %d at %v, want %v", i, tick, want)
+		}
+	}
+
+	// The periods which didn't fit are skipped, keeping the phase.
+	fc.Advance(time.Second)
+	fc.BlockUntil(1)
+	if tick, want := <-ft.Chan(), start.Add(8*time.Second); !tick.Equal(want) {
+		t.Errorf("tick after catching up at %v, want %v", tick, want)
+	}
+}
+
+func TestNewBufferedTickerNonPositive(t *testing.T) {
+	t.Parallel()
+	defer func() {
+		if err := recover(); err != ErrNonPositiveBuffer {
+			t.Errorf("NewBufferedTicker(1s, 0) panicked with %v, want %v", err, ErrNonPositiveBuffer)
+		}
+	}()
+	mustPanic(t, "real NewBufferedTicker(1s, -1)", func() { NewRealClock().NewBufferedTicker(time.Second, -1) })
+	NewFakeClock().NewBufferedTicker(time.Second, 0)
+}
+
+func TestFakeAlignedTicker(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2020, 1, 1, 12, 0, 23, 0, time.UTC)
+	fc := NewFakeClockAt(start)
+	ft := fc.NewAlignedTicker(time.Minute)
+	defer ft.Stop()
+	fc.BlockUntil(1)
+
+	// Nothing is due until the next whole minute.
+	fc.Advance(36 * time.Second)
+	fc.BlockUntil(1)
+	select {
+	case tick := <-ft.Chan():
+		t.Fatalf("unexpected tick at %v", tick)
+	default:
+	}
+
+	for i, want := range []time.Time{
+		time.Date(2020, 1, 1, 12, 1, 0, 0, time.UTC),
+		time.Date(2020, 1, 1, 12, 2, 0, 0, time.UTC),
+	} {
+		fc.Advance(want.Sub(fc.Now()))
+		fc.BlockUntil(1)
+		select {
+		case tick := <-ft.Chan():
+			if !tick.Equal(want) {
+				t.Errorf("tick %d at %v, want %v", i, tick, want)
+			}
+		default:
+			t.Fatalf("expected tick %d at %v", i, want)
+		}
+	}
+}
+
+func TestRealAlignedTicker(t *testing.T) {
+	t.Parallel()
+	const d = 10 * time.Millisecond
+	rt := NewRealClock().NewAlignedTicker(d)
+	defer rt.Stop()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-rt.Chan():
+		case <-time.After(time.Second):
+			t.Fatalf("expected tick %d", i)
+		}
+	}
+}
+
+func TestFakeSkewedTicker(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	// Every third tick is half a second late.
+	ft := fc.NewSkewedTicker(time.Second, func(n int) time.Duration {
+		if n%3 == 0 {
+			return 500 * time.Millisecond
+		}
+		return 0
+	})
+	defer ft.Stop()
+
+	fc.Advance(2 * time.Second)
+	<-ft.Chan()
+	fc.Advance(time.Second)
+	select {
+	case tick := <-ft.Chan():
+		t.Fatalf("unexpected tick at %v, before the skewed third tick", tick)
+	default:
+	}
+	for _, want := range []time.Duration{3500, 4000, 5000, 6500, 7000} {
+		want := start.Add(want * time.Millisecond)
+		if tick := fc.WaitTick(ft); !tick.Equal(want) {
+			t.Errorf("tick at %v, want %v", tick, want)
+		}
+	}
+}
+
+func TestRealSkewedTicker(t *testing.T) {
+	t.Parallel()
+	rt := NewRealClock().NewSkewedTicker(10*time.Millisecond, func(n int) time.Duration {
+		return time.Duration(n) * time.Millisecond
+	})
+	defer rt.Stop()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-rt.Chan():
+		case <-time.After(time.Second):
+			t.Fatalf("expected tick %d", i)
+		}
+	}
+}
+
+func TestActiveTickers(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	if n := fc.ActiveTickers(); n != 0 {
+		t.Errorf("ActiveTickers() = %d for a new clock, want 0", n)
+	}
+
+	ticker := fc.NewTicker(time.Second)
+	burst := fc.NewBurstTicker(time.Second, 1)
+	defer burst.Stop()
+	step := fc.NewStepTicker()
+	if n := fc.ActiveTickers(); n != 3 {
+		t.Errorf("ActiveTickers() = %d, want 3", n)
+	}
+
+	ticker.Stop()
+	step.Stop()
+	if n := fc.ActiveTickers(); n != 1 {
+		t.Errorf("ActiveTickers() = %d after two Stops, want 1", n)
+	}
+
+	// The burst ticker deregisters itself once it has delivered its tick.
+	fc.BlockUntil(1)
+	fc.Advance(time.Second)
+	<-burst.Chan()
+	for deadline := time.Now().Add(time.Second); fc.ActiveTickers() != 0; {
+		if time.Now().After(deadline) {
+			t.Fatalf("ActiveTickers() = %d after the burst, want 0", fc.ActiveTickers())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStepTicker(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	st := fc.NewStepTicker()
+
+	// One tick per move, however far the clock moves.
+	for i, d := range []time.Duration{time.Nanosecond, time.Hour, 0} {
+		fc.Advance(d)
+		select {
+		case tick := <-st.Chan():
+			if want := fc.Now(); !tick.Equal(want) {
+				t.Errorf("advance %d: tick at %v, want %v", i, tick, want)
+			}
+		default:
+			t.Errorf("advance %d: no tick", i)
+		}
+		select {
+		case tick := <-st.Chan():
+			t.Errorf("advance %d: second tick %v", i, tick)
+		default:
+		}
+	}
+	fc.Set(start)
+	if tick := <-st.Chan(); !tick.Equal(start) {
+		t.Errorf("Set: tick at %v, want %v", tick, start)
+	}
+
+	// Unread ticks are coalesced.
+	fc.Advance(time.Second)
+	fc.Advance(time.Second)
+	if n := len(st.Chan()); n != 1 {
+		t.Errorf("got %d buffered ticks after two advances, want 1", n)
+	}
+	<-st.Chan()
+
+	st.Stop()
+	st.Stop()
+	fc.Advance(time.Second)
+	select {
+	case tick := <-st.Chan():
+		t.Errorf("received tick %v after Stop", tick)
+	default:
+	}
+}
+
+func TestWaitTick(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	ticker := fc.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for i := 1; i <= 3; i++ {
+		want := start.Add(time.Duration(i) * time.Second)
+		if tick := fc.WaitTick(ticker); !tick.Equal(want) {
+			t.Errorf("WaitTick() = %v, want %v", tick, want)
+		}
+		if now := fc.Now(); !now.Equal(want) {
+			t.Errorf("Now() after WaitTick = %v, want %v", now, want)
+		}
+	}
+
+	// An unread tick is discarded rather than returned.
+	fc.Advance(time.Second)
+	if tick, want := fc.WaitTick(ticker), start.Add(5*time.Second); !tick.Equal(want) {
+		t.Errorf("WaitTick() with a tick unread = %v, want %v", tick, want)
+	}
+
+	ticker.Stop()
+	mustPanic(t, "WaitTick on a stopped ticker", func() { fc.WaitTick(ticker) })
+	other := NewFakeClock().NewTicker(time.Second)
+	defer other.Stop()
+	mustPanic(t, "WaitTick on another clock's ticker", func() { fc.WaitTick(other) })
+}
+
+func TestWaitTickBuffered(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	ticker := fc.NewBufferedTicker(time.Second, 3)
+	defer ticker.Stop()
+
+	// Fill the buffer with unread ticks.
+	fc.Advance(3 * time.Second)
+	if tick, want := fc.WaitTick(ticker), start.Add(4*time.Second); !tick.Equal(want) {
+		t.Errorf("WaitTick() with a full buffer = %v, want %v", tick, want)
+	}
+	select {
+	case tick := <-ticker.Chan():
+		t.Errorf("unexpected tick %v left after WaitTick", tick)
+	default:
+	}
+}
+
+func TestWaitTickPanics(t *testing.T) {
+	t.Parallel()
+	// wantPanic checks that fn panics with a message containing want.
+	wantPanic := func(name, want string, fn func()) {
+		t.Helper()
+		defer func() {
+			t.Helper()
+			if err, ok := recover().(error); !ok || !strings.Contains(err.Error(), want) {
+				t.Errorf("%s panicked with %v, want a message containing %q", name, err, want)
+			}
+		}()
+		fn()
+	}
+
+	fc := NewFakeClock(WithMaxFiresPerAdvance(1))
+	fc.NewTimer(time.Second)
+	ticker := fc.NewTicker(time.Second)
+	defer ticker.Stop()
+	// The timer uses up the only fire, deferring the tick.
+	wantPanic("WaitTick with the tick deferred", "no tick was delivered", func() { fc.WaitTick(ticker) })
+
+	step := fc.NewStepTicker()
+	defer step.Stop()
+	wantPanic("WaitTick on a step ticker", "unsupported Ticker type", func() { fc.WaitTick(step) })
+	other := NewFakeClock().NewTicker(time.Second)
+	defer other.Stop()
+	wantPanic("WaitTick on another clock's ticker", "different clock", func() { fc.WaitTick(other) })
+}
+
+func TestAdvanceToNextTick(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	start := fc.Now()
+	ticker := fc.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+	before := fc.NewTimer(2 * time.Second)
+	after := fc.NewTimer(4 * time.Second)
+	other := fc.NewTicker(5 * time.Second)
+	defer other.Stop()
+
+	fc.AdvanceToNextTick(ticker)
+	if want := start.Add(3 * time.Second); !fc.Now().Equal(want) {
+		t.Errorf("Now() = %v, want %v", fc.Now(), want)
+	}
+	select {
+	case tick := <-ticker.Chan():
+		if want := start.Add(3 * time.Second); !tick.Equal(want) {
+			t.Errorf("tick at %v, want %v", tick, want)
+		}
+	default:
+		t.Fatalf("ticker didn't tick")
+	}
+	select {
+	case <-before.C():
+	default:
+		t.Errorf("timer due before the tick didn't fire")
+	}
+	select {
+	case <-after.C():
+		t.Errorf("timer due after the tick fired")
+	case <-other.Chan():
+		t.Errorf("other ticker ticked")
+	default:
+	}
+
+	fc.AdvanceToNextTick(ticker)
+	if want := start.Add(6 * time.Second); !fc.Now().Equal(want) {
+		t.Errorf("Now() after the second AdvanceToNextTick = %v, want %v", fc.Now(), want)
+	}
+	ticker.Stop()
+	mustPanic(t, "AdvanceToNextTick on a stopped ticker", func() { fc.AdvanceToNextTick(ticker) })
+}
+
+func TestFakeTickerImmediateConcurrentAdvance(t *testing.T) {
+	t.Parallel()
+	withTimeout(t, 10*time.Second, func() {
+		fc := NewFakeClock()
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					fc.Advance(time.Nanosecond)
+				}
+			}
+		}()
+		// An Advance between registering the ticker and buffering its first
+		// tick would fill the channel and block the constructor.
+		for i := 0; i < 1000; i++ {
+			fc.NewTickerImmediate(time.Nanosecond).Stop()
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package clockwork
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TimeoutGroup is like context.WithTimeout, but measures the timeout on c, so
+// that a FakeClock can drive it. It suits the context passed to
+// errgroup.WithContext, where the group should give up once d has elapsed.
+//
+// The returned context's Err is context.DeadlineExceeded once d has elapsed,
+// and contexts derived from it see the same error. Its Deadline is Now()+d on
+// c. Calling the CancelFunc stops the timer straight away, releasing its
+// sleeper on a FakeClock, and like any CancelFunc it may be called more than
+// once.
+func TimeoutGroup(ctx context.Context, c Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	return c.DeadlineContext(ctx, c.Now().Add(d))
+}
+
+// timeoutCtx is a context which is done when its parent is, or when its timer
+// fires. It doesn't expose the internals of a context.WithCancel, so contexts
+// derived from it watch it with a goroutine and copy its Err when it is done,
+// which lets them report context.DeadlineExceeded.
+type timeoutCtx struct {
+	parent   context.Context
+	deadline time.Time
+	done     chan struct{}
+
+	timer Timer // set before the context is returned
+
+	l   sync.Mutex // Guards err, and deadline once the context is returned
+	err error      // nil until done
+}
+
+func (tc *timeoutCtx) Deadline() (time.Time, bool) {
+	tc.l.Lock()
+	deadline := tc.deadline
+	tc.l.Unlock()
+	if parent, ok := tc.parent.Deadline(); ok && parent.Before(deadline) {
+		return parent, true
+	}
+	return deadline, true
+}
+
+func (tc *timeoutCtx) Done() <-chan struct{} { return tc.done }
+
+func (tc *timeoutCtx) Err() error {
+	tc.l.Lock()
+	defer tc.l.Unlock()
+	return tc.err
+}
+
+func (tc *timeoutCtx) Value(key interface{}) interface{} { return tc.parent.Value(key) }
+
+// cancel makes tc done with err, unless it already is, and stops its timer.
+func (tc *timeoutCtx) cancel(err error) {
+	tc.l.Lock()
+	finished := tc.finish(err)
+	tc.l.Unlock()
+	// tc.l is released first, as the timer may be firing, and expire takes it.
+	if finished {
+		tc.timer.Stop()
+	}
+}
+
+// expire makes tc done because its timer has fired, unless it already is. It
+// leaves the timer alone, as it is called while the timer is firing.
+func (tc *timeoutCtx) expire() {
+	tc.l.Lock()
+	defer tc.l.Unlock()
+	tc.finish(context.DeadlineExceeded)
+}
+
+// expireTimeout is the callback of a fake DeadlineContext's sleeper, whose arg
+// is the context.
+func expireTimeout(arg interface{}, _ time.Time) {
+	arg.(*timeoutCtx).expire()
+}
+
+// shift moves tc's deadline by d, when a FakeClock jumps.
+func (tc *timeoutCtx) shift(d time.Duration) {
+	tc.l.Lock()
+	defer tc.l.Unlock()
+	tc.deadline = tc.deadline.Add(d)
+}
+
+// finish makes tc done with err, unless it already is, and reports whether
+// it did. The caller must hold tc.l.
+func (tc *timeoutCtx) finish(err error) bool {
+	if tc.err != nil {
+		return false
+	}
+	tc.err = err
+	close(tc.done)
+	return true
+}
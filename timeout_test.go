@@ -0,0 +1,143 @@
+package clockwork
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeoutGroup(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	ctx, cancel := TimeoutGroup(context.Background(), fc, time.Second)
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(fc.Now().Add(time.Second)) {
+		t.Errorf("Deadline() = %v, %v, want %v, true", deadline, ok, fc.Now().Add(time.Second))
+	}
+	// Derived the way errgroup.WithContext derives the group's context.
+	group, groupCancel := context.WithCancel(ctx)
+	defer groupCancel()
+
+	fc.BlockUntil(1)
+	fc.Advance(time.Second - 1)
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("Err() = %v before the timeout", err)
+	}
+	fc.Advance(1)
+	select {
+	case <-group.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("group context not done after the timeout")
+	}
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Errorf("Err() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err := group.Err(); err != context.DeadlineExceeded {
+		t.Errorf("group Err() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestTimeoutGroupCancelledEarly(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	ctx, cancel := TimeoutGroup(context.Background(), fc, time.Second)
+	fc.BlockUntil(1)
+
+	cancel()
+	cancel()
+	// The timer's sleeper is released by cancel.
+	fc.BlockUntil(0)
+	if err := ctx.Err(); err != context.Canceled {
+		t.Errorf("Err() = %v, want %v", err, context.Canceled)
+	}
+	fc.Advance(time.Second)
+	if err := ctx.Err(); err != context.Canceled {
+		t.Errorf("Err() after the timeout = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestTimeoutGroupParentCancelled(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := TimeoutGroup(parent, fc, time.Second)
+	defer cancel()
+	fc.BlockUntil(1)
+
+	cancelParent()
+	<-ctx.Done()
+	fc.BlockUntil(0)
+	if err := ctx.Err(); err != context.Canceled {
+		t.Errorf("Err() = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestDeadlineContext(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	deadline := fc.Now().Add(time.Minute)
+	ctx, cancel := fc.DeadlineContext(context.Background(), deadline)
+	defer cancel()
+	if got, ok := ctx.Deadline(); !ok || !got.Equal(deadline) {
+		t.Errorf("Deadline() = %v, %v, want %v, true", got, ok, deadline)
+	}
+
+	fc.Advance(time.Minute - 1)
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("Err() = %v before the deadline", err)
+	}
+	fc.Advance(1)
+	// Done is closed by the Advance which reaches the deadline.
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatalf("context not done once Advance reached the deadline")
+	}
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Errorf("Err() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	fc.BlockUntil(0)
+}
+
+func TestDeadlineContextCancelledEarly(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	ctx, cancel := fc.DeadlineContext(context.Background(), fc.Now().Add(time.Minute))
+	fc.BlockUntil(1)
+
+	cancel()
+	// The sleeper is released by cancel.
+	fc.BlockUntil(0)
+	if err := ctx.Err(); err != context.Canceled {
+		t.Errorf("Err() = %v, want %v", err, context.Canceled)
+	}
+	fc.Advance(time.Minute)
+	if err := ctx.Err(); err != context.Canceled {
+		t.Errorf("Err() after the deadline = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestDeadlineContextPast(t *testing.T) {
+	t.Parallel()
+	fc := NewFakeClock()
+	ctx, cancel := fc.DeadlineContext(context.Background(), fc.Now())
+	defer cancel()
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Errorf("Err() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRealClockDeadlineContext(t *testing.T) {
+	t.Parallel()
+	c := NewRealClock()
+	ctx, cancel := c.DeadlineContext(context.Background(), c.Now().Add(time.Millisecond))
+	defer cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("context not done after the deadline")
+	}
+	if err := ctx.Err(); err != context.DeadlineExceeded {
+		t.Errorf("Err() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,50 @@
+package clockwork
+
+import "time"
+
+// runWatchdog reports sleepers which have been pending for longer than
+// fc.watchdog of real time to fc.watchdogHandler, checking twice per watchdog
+// period, until stopWatchdog is called.
+func (fc *fakeClock) runWatchdog() {
+	defer close(fc.watchdogExited)
+	period := fc.watchdog / 2
+	if period <= 0 {
+		period = fc.watchdog
+	}
+	t := time.NewTicker(period)
+	defer t.Stop()
+	for {
+		select {
+		case <-fc.watchdogStop:
+			return
+		case now := <-t.C:
+			for _, s := range fc.overdue(now) {
+				fc.watchdogHandler(s.label, s.Until())
+			}
+		}
+	}
+}
+
+// overdue returns the pending sleepers which were armed more than fc.watchdog
+// before now, in real time, and haven't already been reported, marking them
+// as reported.
+func (fc *fakeClock) overdue(now time.Time) []*sleeper {
+	fc.l.Lock()
+	defer fc.l.Unlock()
+	var overdue []*sleeper
+	for _, s := range fc.sleepers {
+		if !s.reported && now.Sub(s.armed) > fc.watchdog {
+			s.reported = true
+			overdue = append(overdue, s)
+		}
+	}
+	return overdue
+}
+
+// stopWatchdog stops the watchdog goroutine, if there is one. It doesn't wait
+// for it to return.
+func (fc *fakeClock) stopWatchdog() {
+	if fc.watchdogStop != nil {
+		fc.watchdogOnce.Do(func() { close(fc.watchdogStop) })
+	}
+}